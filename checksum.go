@@ -0,0 +1,123 @@
+package yandexdisk
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ChecksumError is returned when the content of a transfer does not match the
+// MD5 or SHA256 reported by the API. It matches ErrChecksumMismatch.
+type ChecksumError struct {
+	Path      string
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected %s %s, got %s", e.Path, e.Algorithm, e.Expected, e.Actual)
+}
+
+func (e *ChecksumError) Is(target error) bool {
+	return target == ErrChecksumMismatch
+}
+
+// WithChecksumVerification makes uploads and downloads hash the data they
+// transfer and compare it with the MD5 and SHA256 of the resource returned by
+// GetMeta: after an upload completes, and before a download starts.
+func WithChecksumVerification() Option {
+	return func(c *Client) {
+		c.verifyChecksums = true
+	}
+}
+
+type checksums struct {
+	md5    hash.Hash
+	sha256 hash.Hash
+	w      io.Writer
+}
+
+func newChecksums() *checksums {
+	h := &checksums{md5: md5.New(), sha256: sha256.New()}
+	h.w = io.MultiWriter(h.md5, h.sha256)
+	return h
+}
+
+func (h *checksums) Write(p []byte) (int, error) {
+	return h.w.Write(p)
+}
+
+// verify compares the hashed data with the checksums of resource. Checksums
+// the API did not report are skipped.
+func (h *checksums) verify(resource *Resource) error {
+	actual := hex.EncodeToString(h.md5.Sum(nil))
+	if resource.MD5 != "" && !strings.EqualFold(resource.MD5, actual) {
+		return &ChecksumError{Path: resource.Path, Algorithm: "md5", Expected: resource.MD5, Actual: actual}
+	}
+
+	actual = hex.EncodeToString(h.sha256.Sum(nil))
+	if resource.SHA256 != "" && !strings.EqualFold(resource.SHA256, actual) {
+		return &ChecksumError{Path: resource.Path, Algorithm: "sha256", Expected: resource.SHA256, Actual: actual}
+	}
+	return nil
+}
+
+func verifyFile(localPath string, resource *Resource) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to read local file: %w", err)
+	}
+	defer file.Close()
+
+	h := newChecksums()
+	if _, err := io.Copy(h, file); err != nil {
+		return fmt.Errorf("failed to read local file: %w", err)
+	}
+	return h.verify(resource)
+}
+
+// verifying wraps body so that reading it to the end checks it against
+// resource. It returns body unchanged when verification is disabled.
+func (c *Client) verifying(body io.ReadCloser, resource *Resource) io.ReadCloser {
+	if !c.verifyChecksums || resource == nil {
+		return body
+	}
+	return &verifyingReader{ReadCloser: body, h: newChecksums(), resource: resource}
+}
+
+func (c *Client) getPublicMeta(ctx context.Context, publicKey string, path *string) (*Resource, error) {
+	var params map[string]string
+	if path != nil {
+		params = map[string]string{"path": *path}
+	}
+	return c.GetPublicResourceMetaContext(ctx, publicKey, params)
+}
+
+// verifyingReader hashes everything read through it and reports a
+// *ChecksumError instead of io.EOF when the content does not match.
+type verifyingReader struct {
+	io.ReadCloser
+	h        *checksums
+	resource *Resource
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.h.Write(p[:n])
+	if err == io.EOF {
+		if verr := r.h.verify(r.resource); verr != nil {
+			return n, verr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,63 @@
+package yandexdisk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadVerifiesChecksum(t *testing.T) {
+	server := newFileServer(t, "")
+	client := NewClient("test-token", WithBaseURL(server.URL), WithChecksumVerification())
+
+	result, err := client.Upload(context.Background(), io.MultiReader(strings.NewReader("hello")), "/disk/file.txt", nil)
+	assert.NoError(t, err)
+	assert.True(t, result.Success)
+}
+
+func TestDownloadChecksumMismatch(t *testing.T) {
+	server := newFileServer(t, "corrupted")
+	server.meta = []byte("original")
+	client := NewClient("test-token", WithBaseURL(server.URL), WithChecksumVerification())
+
+	_, err := client.DownloadTo(context.Background(), "/disk/file.txt", io.Discard)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	var checksumErr *ChecksumError
+	assert.True(t, errors.As(err, &checksumErr))
+	assert.Equal(t, "md5", checksumErr.Algorithm)
+	assert.Equal(t, "disk:/file.txt", checksumErr.Path)
+
+	body, _, err := client.Download(context.Background(), "/disk/file.txt")
+	assert.NoError(t, err)
+	_, err = io.ReadAll(body)
+	body.Close()
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+}
+
+func TestDownloadFileVerifiesChecksum(t *testing.T) {
+	server := newFileServer(t, "original")
+	client := NewClient("test-token", WithBaseURL(server.URL), WithChecksumVerification())
+
+	localPath := filepath.Join(t.TempDir(), "file.txt")
+	assert.NoError(t, client.DownloadFile("/disk/file.txt", localPath))
+
+	data, _ := os.ReadFile(localPath)
+	assert.Equal(t, "original", string(data))
+
+	// A corrupted partial file is discarded rather than renamed.
+	localPath = filepath.Join(t.TempDir(), "file.txt")
+	assert.NoError(t, os.WriteFile(localPath+partialSuffix, []byte("ORIG"), 0644))
+	assert.NoError(t, os.WriteFile(localPath+partialSuffix+validatorSuffix, []byte(server.etag()), 0644))
+	err := client.DownloadFile("/disk/file.txt", localPath)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.NoFileExists(t, localPath)
+	assert.NoFileExists(t, localPath+partialSuffix)
+	assert.NoFileExists(t, localPath+partialSuffix+validatorSuffix)
+}
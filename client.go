@@ -2,14 +2,13 @@ package yandexdisk
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
 	"time"
 )
 
@@ -18,20 +17,41 @@ const (
 )
 
 type Client struct {
-	accessToken string
-	httpClient  *http.Client
-}
-
-func NewClient(accessToken string) *Client {
-	return &Client{
-		accessToken: accessToken,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
-		},
-	}
+	tokenSource        TokenSource
+	baseURL            string
+	userAgent          string
+	headers            http.Header
+	httpClient         *http.Client
+	transport          http.RoundTripper
+	insecureSkipVerify bool
+	timeout            time.Duration
+	transferTimeout    time.Duration
+	retryPolicy        RetryPolicy
+	apiLimiter         *limiter
+	transferLimiter    *limiter
+	progress           ProgressFunc
+	maxResumes         int
+	verifyChecksums    bool
+	waitOptions        WaitOptions
+	links              *linkCache
+}
+
+func NewClient(accessToken string, opts ...Option) *Client {
+	c := &Client{
+		tokenSource: StaticTokenSource(&Token{AccessToken: accessToken}),
+		baseURL:     APIBaseURL,
+		userAgent:   DefaultUserAgent,
+		headers:     http.Header{},
+		timeout:     DefaultTimeout,
+		retryPolicy: DefaultRetryPolicy,
+		maxResumes:  DefaultMaxResumes,
+		links:       &linkCache{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.initHTTPClient()
+	return c
 }
 
 func GetAuthorizationURL(clientID string) string {
@@ -41,57 +61,158 @@ func GetAuthorizationURL(clientID string) string {
 	return "https://oauth.yandex.ru/authorize?" + params.Encode()
 }
 
-func (c *Client) request(method, endpoint string, queryParams url.Values, body interface{}) ([]byte, error) {
-	reqURL := APIBaseURL + endpoint
+func (c *Client) newRequest(ctx context.Context, method, reqURL string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range c.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	return req, nil
+}
+
+func (c *Client) withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// do sends the request produced by newReq, retrying it according to the
+// client's RetryPolicy. newReq is called once per attempt and must return a
+// request with a fresh body. Every attempt waits for the rate limiter lim.
+// A request rejected with 401 Unauthorized is sent once more if the token
+// source provides a new token.
+func (c *Client) do(ctx context.Context, lim *limiter, replay replayMode, newReq func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	reauthorized := false
+	for attempt := 1; ; attempt++ {
+		if err := lim.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		req, err := newReq(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if resp != nil {
+			if resp.StatusCode == http.StatusTooManyRequests {
+				lim.throttle()
+			} else if resp.StatusCode < 300 {
+				lim.recover()
+			}
+
+			if resp.StatusCode == http.StatusUnauthorized && !reauthorized && replay != replayNever && c.reauthorize(req) {
+				drainBody(resp)
+				reauthorized = true
+				attempt--
+				continue
+			}
+		}
+
+		delay, retry := c.retryPolicy.shouldRetry(ctx, attempt, replay, resp, err)
+		if !retry {
+			return resp, err
+		}
+		if resp != nil {
+			drainBody(resp)
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) request(ctx context.Context, method, endpoint string, queryParams url.Values, body interface{}) ([]byte, error) {
+	data, _, err := c.requestStatus(ctx, method, endpoint, queryParams, body)
+	return data, err
+}
+
+// requestStatus is like request but also returns the status code of a
+// successful response.
+func (c *Client) requestStatus(ctx context.Context, method, endpoint string, queryParams url.Values, body interface{}) ([]byte, int, error) {
+	ctx, cancel := c.withTimeout(ctx, c.timeout)
+	defer cancel()
+
+	reqURL := c.baseURL + endpoint
 	if queryParams != nil {
 		reqURL += "?" + queryParams.Encode()
 	}
 
-	var reqBody io.Reader
+	var jsonData []byte
 	if body != nil {
-		jsonData, err := json.Marshal(body)
+		var err error
+		jsonData, err = json.Marshal(body)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, 0, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonData)
 	}
 
-	req, err := http.NewRequest(method, reqURL, reqBody)
+	release, err := c.apiLimiter.acquire(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, 0, fmt.Errorf("request failed: %w", err)
 	}
+	defer release()
 
-	req.Header.Set("Authorization", "OAuth "+c.accessToken)
-	req.Header.Set("Accept", "application/json")
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
+	resp, err := c.do(ctx, c.apiLimiter, replayModeFor(method), func(ctx context.Context) (*http.Request, error) {
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(jsonData)
+		}
 
-	resp, err := c.httpClient.Do(req)
+		req, err := c.newRequest(ctx, method, reqURL, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		if err := c.authorize(req); err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		return req, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, 0, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, 0, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		var apiError APIError
-		if err := json.Unmarshal(respBody, &apiError); err == nil && apiError.Message != "" {
-			apiError.StatusCode = resp.StatusCode
-			return nil, &apiError
+		if err := json.Unmarshal(respBody, &apiError); err != nil || (apiError.Message == "" && apiError.ErrorCode == "") {
+			apiError = APIError{Message: fmt.Sprintf("API request failed with status %d: %s", resp.StatusCode, string(respBody))}
 		}
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+		apiError.StatusCode = resp.StatusCode
+		apiError.RequestID = requestID(resp.Header)
+		return nil, 0, &apiError
 	}
 
-	return respBody, nil
+	return respBody, resp.StatusCode, nil
 }
 
 func (c *Client) GetCapacity() (*DiskInfo, error) {
-	data, err := c.request("GET", "/", nil, nil)
+	return c.GetCapacityContext(context.Background())
+}
+
+func (c *Client) GetCapacityContext(ctx context.Context) (*DiskInfo, error) {
+	data, err := c.request(ctx, "GET", "/", nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -105,13 +226,17 @@ func (c *Client) GetCapacity() (*DiskInfo, error) {
 }
 
 func (c *Client) GetMeta(path string, params map[string]string) (*Resource, error) {
+	return c.GetMetaContext(context.Background(), path, params)
+}
+
+func (c *Client) GetMetaContext(ctx context.Context, path string, params map[string]string) (*Resource, error) {
 	queryParams := url.Values{}
 	queryParams.Set("path", path)
 	for k, v := range params {
 		queryParams.Set(k, v)
 	}
 
-	data, err := c.request("GET", "/resources", queryParams, nil)
+	data, err := c.request(ctx, "GET", "/resources", queryParams, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -125,12 +250,16 @@ func (c *Client) GetMeta(path string, params map[string]string) (*Resource, erro
 }
 
 func (c *Client) AddMeta(path string, customProperties map[string]interface{}) (*Resource, error) {
+	return c.AddMetaContext(context.Background(), path, customProperties)
+}
+
+func (c *Client) AddMetaContext(ctx context.Context, path string, customProperties map[string]interface{}) (*Resource, error) {
 	body := map[string]interface{}{
 		"path":              path,
 		"custom_properties": customProperties,
 	}
 
-	data, err := c.request("PATCH", "/resources", nil, body)
+	data, err := c.request(ctx, "PATCH", "/resources", nil, body)
 	if err != nil {
 		return nil, err
 	}
@@ -144,11 +273,15 @@ func (c *Client) AddMeta(path string, customProperties map[string]interface{}) (
 }
 
 func (c *Client) GetAllFiles(limit, offset int) (*FilesList, error) {
+	return c.GetAllFilesContext(context.Background(), limit, offset)
+}
+
+func (c *Client) GetAllFilesContext(ctx context.Context, limit, offset int) (*FilesList, error) {
 	queryParams := url.Values{}
 	queryParams.Set("limit", fmt.Sprintf("%d", limit))
 	queryParams.Set("offset", fmt.Sprintf("%d", offset))
 
-	data, err := c.request("GET", "/resources/files", queryParams, nil)
+	data, err := c.request(ctx, "GET", "/resources/files", queryParams, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -162,11 +295,15 @@ func (c *Client) GetAllFiles(limit, offset int) (*FilesList, error) {
 }
 
 func (c *Client) GetRecentUploads(limit, offset int) (*FilesList, error) {
+	return c.GetRecentUploadsContext(context.Background(), limit, offset)
+}
+
+func (c *Client) GetRecentUploadsContext(ctx context.Context, limit, offset int) (*FilesList, error) {
 	queryParams := url.Values{}
 	queryParams.Set("limit", fmt.Sprintf("%d", limit))
 	queryParams.Set("offset", fmt.Sprintf("%d", offset))
 
-	data, err := c.request("GET", "/resources/last-uploaded", queryParams, nil)
+	data, err := c.request(ctx, "GET", "/resources/last-uploaded", queryParams, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -180,11 +317,15 @@ func (c *Client) GetRecentUploads(limit, offset int) (*FilesList, error) {
 }
 
 func (c *Client) GetRecentPublished(limit, offset int) (*FilesList, error) {
+	return c.GetRecentPublishedContext(context.Background(), limit, offset)
+}
+
+func (c *Client) GetRecentPublishedContext(ctx context.Context, limit, offset int) (*FilesList, error) {
 	queryParams := url.Values{}
 	queryParams.Set("limit", fmt.Sprintf("%d", limit))
 	queryParams.Set("offset", fmt.Sprintf("%d", offset))
 
-	data, err := c.request("GET", "/resources/public", queryParams, nil)
+	data, err := c.request(ctx, "GET", "/resources/public", queryParams, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -198,10 +339,14 @@ func (c *Client) GetRecentPublished(limit, offset int) (*FilesList, error) {
 }
 
 func (c *Client) CreateFolder(path string) (*Resource, error) {
+	return c.CreateFolderContext(context.Background(), path)
+}
+
+func (c *Client) CreateFolderContext(ctx context.Context, path string) (*Resource, error) {
 	queryParams := url.Values{}
 	queryParams.Set("path", path)
 
-	data, err := c.request("PUT", "/resources", queryParams, nil)
+	data, err := c.request(ctx, "PUT", "/resources", queryParams, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -215,117 +360,52 @@ func (c *Client) CreateFolder(path string) (*Resource, error) {
 }
 
 func (c *Client) UploadFile(localFilePath, remotePath string, overwrite bool) (*UploadResult, error) {
-	if _, err := os.Stat(localFilePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("local file not found: %s", localFilePath)
-	}
-
-	queryParams := url.Values{}
-	queryParams.Set("path", remotePath)
-	if overwrite {
-		queryParams.Set("overwrite", "true")
-	} else {
-		queryParams.Set("overwrite", "false")
-	}
-
-	data, err := c.request("GET", "/resources/upload", queryParams, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var uploadURL struct {
-		Href      string `json:"href"`
-		Method    string `json:"method"`
-		Templated bool   `json:"templated"`
-	}
-	if err := json.Unmarshal(data, &uploadURL); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal upload URL: %w", err)
-	}
+	return c.UploadFileContext(context.Background(), localFilePath, remotePath, overwrite)
+}
 
-	if uploadURL.Href == "" {
-		return nil, fmt.Errorf("failed to get upload URL")
+func (c *Client) UploadFileContext(ctx context.Context, localFilePath, remotePath string, overwrite bool) (*UploadResult, error) {
+	file, err := os.Open(localFilePath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("local file not found: %s", localFilePath)
 	}
-
-	fileContent, err := os.ReadFile(localFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read local file: %w", err)
 	}
+	defer file.Close()
 
-	req, err := http.NewRequest("PUT", uploadURL.Href, bytes.NewBuffer(fileContent))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create upload request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "OAuth "+c.accessToken)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("upload failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	return &UploadResult{
-		Status:  resp.StatusCode,
-		Success: resp.StatusCode == 201,
-	}, nil
+	return c.Upload(ctx, file, remotePath, &UploadOptions{Overwrite: overwrite})
 }
 
 func (c *Client) DownloadFile(remotePath, localPath string) error {
-	queryParams := url.Values{}
-	queryParams.Set("path", remotePath)
-
-	data, err := c.request("GET", "/resources/download", queryParams, nil)
-	if err != nil {
-		return err
-	}
-
-	var downloadURL struct {
-		Href   string `json:"href"`
-		Method string `json:"method"`
-	}
-	if err := json.Unmarshal(data, &downloadURL); err != nil {
-		return fmt.Errorf("failed to unmarshal download URL: %w", err)
-	}
-
-	if downloadURL.Href == "" {
-		return fmt.Errorf("failed to get download URL for: %s", remotePath)
-	}
-
-	req, err := http.NewRequest("GET", downloadURL.Href, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create download request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "OAuth "+c.accessToken)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("download failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("download failed with status: %d", resp.StatusCode)
-	}
+	return c.DownloadFileContext(context.Background(), remotePath, localPath)
+}
 
-	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
+func (c *Client) DownloadFileContext(ctx context.Context, remotePath, localPath string) error {
+	return c.downloadFile(ctx, localPath, true, func(ctx context.Context) (*Link, error) {
+		return c.getDownloadLink(ctx, remotePath)
+	}, func(ctx context.Context) (*Resource, error) {
+		return c.GetMetaContext(ctx, remotePath, nil)
+	})
+}
 
-	outFile, err := os.Create(localPath)
-	if err != nil {
-		return fmt.Errorf("failed to create local file: %w", err)
-	}
-	defer outFile.Close()
+func (c *Client) Copy(fromPath, toPath string, overwrite bool) (*Resource, error) {
+	return c.CopyContext(context.Background(), fromPath, toPath, overwrite)
+}
 
-	_, err = io.Copy(outFile, resp.Body)
+// CopyContext copies fromPath to toPath and returns the new resource. When
+// the API copies in the background, it waits for the operation to finish.
+func (c *Client) CopyContext(ctx context.Context, fromPath, toPath string, overwrite bool) (*Resource, error) {
+	op, err := c.CopyAsync(ctx, fromPath, toPath, overwrite)
 	if err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+		return nil, err
 	}
-
-	return nil
+	return c.waitResource(ctx, op, toPath)
 }
 
-func (c *Client) Copy(fromPath, toPath string, overwrite bool) (*Resource, error) {
+// CopyAsync starts copying fromPath to toPath. It returns the operation to
+// pass to WaitOperation if the copy continues in the background, or nil if it
+// has already completed.
+func (c *Client) CopyAsync(ctx context.Context, fromPath, toPath string, overwrite bool) (*Operation, error) {
 	queryParams := url.Values{}
 	queryParams.Set("from", fromPath)
 	queryParams.Set("path", toPath)
@@ -335,20 +415,27 @@ func (c *Client) Copy(fromPath, toPath string, overwrite bool) (*Resource, error
 		queryParams.Set("overwrite", "false")
 	}
 
-	data, err := c.request("POST", "/resources/copy", queryParams, nil)
+	return c.asyncRequest(ctx, "POST", "/resources/copy", queryParams)
+}
+
+func (c *Client) Move(fromPath, toPath string, overwrite bool) (*Resource, error) {
+	return c.MoveContext(context.Background(), fromPath, toPath, overwrite)
+}
+
+// MoveContext moves fromPath to toPath and returns the moved resource. When
+// the API moves in the background, it waits for the operation to finish.
+func (c *Client) MoveContext(ctx context.Context, fromPath, toPath string, overwrite bool) (*Resource, error) {
+	op, err := c.MoveAsync(ctx, fromPath, toPath, overwrite)
 	if err != nil {
 		return nil, err
 	}
-
-	var resource Resource
-	if err := json.Unmarshal(data, &resource); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal resource: %w", err)
-	}
-
-	return &resource, nil
+	return c.waitResource(ctx, op, toPath)
 }
 
-func (c *Client) Move(fromPath, toPath string, overwrite bool) (*Resource, error) {
+// MoveAsync starts moving fromPath to toPath. It returns the operation to
+// pass to WaitOperation if the move continues in the background, or nil if it
+// has already completed.
+func (c *Client) MoveAsync(ctx context.Context, fromPath, toPath string, overwrite bool) (*Operation, error) {
 	queryParams := url.Values{}
 	queryParams.Set("from", fromPath)
 	queryParams.Set("path", toPath)
@@ -358,20 +445,28 @@ func (c *Client) Move(fromPath, toPath string, overwrite bool) (*Resource, error
 		queryParams.Set("overwrite", "false")
 	}
 
-	data, err := c.request("POST", "/resources/move", queryParams, nil)
-	if err != nil {
-		return nil, err
-	}
+	return c.asyncRequest(ctx, "POST", "/resources/move", queryParams)
+}
 
-	var resource Resource
-	if err := json.Unmarshal(data, &resource); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal resource: %w", err)
-	}
+func (c *Client) Delete(path string, permanently bool) error {
+	return c.DeleteContext(context.Background(), path, permanently)
+}
 
-	return &resource, nil
+// DeleteContext deletes path, waiting for the operation to finish when the
+// API deletes in the background.
+func (c *Client) DeleteContext(ctx context.Context, path string, permanently bool) error {
+	op, err := c.DeleteAsync(ctx, path, permanently)
+	if err != nil || op == nil {
+		return err
+	}
+	_, err = c.WaitOperation(ctx, op, nil)
+	return err
 }
 
-func (c *Client) Delete(path string, permanently bool) error {
+// DeleteAsync starts deleting path. It returns the operation to pass to
+// WaitOperation if the deletion continues in the background, or nil if it
+// has already completed.
+func (c *Client) DeleteAsync(ctx context.Context, path string, permanently bool) (*Operation, error) {
 	queryParams := url.Values{}
 	queryParams.Set("path", path)
 	if permanently {
@@ -380,15 +475,18 @@ func (c *Client) Delete(path string, permanently bool) error {
 		queryParams.Set("permanently", "false")
 	}
 
-	_, err := c.request("DELETE", "/resources", queryParams, nil)
-	return err
+	return c.asyncRequest(ctx, "DELETE", "/resources", queryParams)
 }
 
 func (c *Client) Publish(path string) (*Resource, error) {
+	return c.PublishContext(context.Background(), path)
+}
+
+func (c *Client) PublishContext(ctx context.Context, path string) (*Resource, error) {
 	queryParams := url.Values{}
 	queryParams.Set("path", path)
 
-	data, err := c.request("PUT", "/resources/publish", queryParams, nil)
+	data, err := c.request(ctx, "PUT", "/resources/publish", queryParams, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -402,10 +500,14 @@ func (c *Client) Publish(path string) (*Resource, error) {
 }
 
 func (c *Client) Unpublish(path string) (*Resource, error) {
+	return c.UnpublishContext(context.Background(), path)
+}
+
+func (c *Client) UnpublishContext(ctx context.Context, path string) (*Resource, error) {
 	queryParams := url.Values{}
 	queryParams.Set("path", path)
 
-	data, err := c.request("PUT", "/resources/unpublish", queryParams, nil)
+	data, err := c.request(ctx, "PUT", "/resources/unpublish", queryParams, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -419,13 +521,17 @@ func (c *Client) Unpublish(path string) (*Resource, error) {
 }
 
 func (c *Client) GetPublicResourceMeta(publicKey string, params map[string]string) (*Resource, error) {
+	return c.GetPublicResourceMetaContext(context.Background(), publicKey, params)
+}
+
+func (c *Client) GetPublicResourceMetaContext(ctx context.Context, publicKey string, params map[string]string) (*Resource, error) {
 	queryParams := url.Values{}
 	queryParams.Set("public_key", publicKey)
 	for k, v := range params {
 		queryParams.Set(k, v)
 	}
 
-	data, err := c.request("GET", "/public/resources", queryParams, nil)
+	data, err := c.request(ctx, "GET", "/public/resources", queryParams, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -439,58 +545,22 @@ func (c *Client) GetPublicResourceMeta(publicKey string, params map[string]strin
 }
 
 func (c *Client) DownloadPublicResource(publicKey, localPath string, path *string) error {
-	queryParams := url.Values{}
-	queryParams.Set("public_key", publicKey)
-	if path != nil {
-		queryParams.Set("path", *path)
-	}
-
-	data, err := c.request("GET", "/public/resources/download", queryParams, nil)
-	if err != nil {
-		return err
-	}
-
-	var downloadURL struct {
-		Href   string `json:"href"`
-		Method string `json:"method"`
-	}
-	if err := json.Unmarshal(data, &downloadURL); err != nil {
-		return fmt.Errorf("failed to unmarshal download URL: %w", err)
-	}
-
-	if downloadURL.Href == "" {
-		return fmt.Errorf("failed to get download URL for public resource")
-	}
-
-	resp, err := c.httpClient.Get(downloadURL.Href)
-	if err != nil {
-		return fmt.Errorf("download failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("download failed with status: %d", resp.StatusCode)
-	}
-
-	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	outFile, err := os.Create(localPath)
-	if err != nil {
-		return fmt.Errorf("failed to create local file: %w", err)
-	}
-	defer outFile.Close()
-
-	_, err = io.Copy(outFile, resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
+	return c.DownloadPublicResourceContext(context.Background(), publicKey, localPath, path)
+}
 
-	return nil
+func (c *Client) DownloadPublicResourceContext(ctx context.Context, publicKey, localPath string, path *string) error {
+	return c.downloadFile(ctx, localPath, false, func(ctx context.Context) (*Link, error) {
+		return c.getPublicDownloadLink(ctx, publicKey, path)
+	}, func(ctx context.Context) (*Resource, error) {
+		return c.getPublicMeta(ctx, publicKey, path)
+	})
 }
 
 func (c *Client) SavePublicResource(publicKey string, name, path *string) (*Resource, error) {
+	return c.SavePublicResourceContext(context.Background(), publicKey, name, path)
+}
+
+func (c *Client) SavePublicResourceContext(ctx context.Context, publicKey string, name, path *string) (*Resource, error) {
 	queryParams := url.Values{}
 	queryParams.Set("public_key", publicKey)
 	if name != nil {
@@ -500,7 +570,7 @@ func (c *Client) SavePublicResource(publicKey string, name, path *string) (*Reso
 		queryParams.Set("path", *path)
 	}
 
-	data, err := c.request("POST", "/public/resources/save", queryParams, nil)
+	data, err := c.request(ctx, "POST", "/public/resources/save", queryParams, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -513,58 +583,85 @@ func (c *Client) SavePublicResource(publicKey string, name, path *string) (*Reso
 	return &resource, nil
 }
 
-func (c *Client) GetAvailablePublicSettings() (map[string]interface{}, error) {
-	data, err := c.request("GET", "/public/resources/public-settings/available", nil, nil)
+func (c *Client) GetAvailablePublicSettings() (*AvailablePublicSettings, error) {
+	return c.GetAvailablePublicSettingsContext(context.Background())
+}
+
+func (c *Client) GetAvailablePublicSettingsContext(ctx context.Context) (*AvailablePublicSettings, error) {
+	data, err := c.request(ctx, "GET", "/public/resources/public-settings/available", nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var settings map[string]interface{}
+	var settings AvailablePublicSettings
 	if err := json.Unmarshal(data, &settings); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal settings: %w", err)
 	}
 
-	return settings, nil
+	return &settings, nil
+}
+
+func (c *Client) GetPublicSettings(path string, allowAddressAccess bool) (*PublicSettings, error) {
+	return c.GetPublicSettingsContext(context.Background(), path, allowAddressAccess)
 }
 
-func (c *Client) GetPublicSettings(path string, allowAddressAccess bool) (map[string]interface{}, error) {
+func (c *Client) GetPublicSettingsContext(ctx context.Context, path string, allowAddressAccess bool) (*PublicSettings, error) {
 	queryParams := url.Values{}
 	queryParams.Set("path", path)
 	if allowAddressAccess {
 		queryParams.Set("allow_address_access", "true")
 	}
 
-	data, err := c.request("GET", "/public/resources/public-settings", queryParams, nil)
+	data, err := c.request(ctx, "GET", "/public/resources/public-settings", queryParams, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var settings map[string]interface{}
+	var settings PublicSettings
 	if err := json.Unmarshal(data, &settings); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal settings: %w", err)
 	}
 
-	return settings, nil
+	return &settings, nil
 }
 
-func (c *Client) ChangePublicSettings(path string, settings map[string]interface{}) (map[string]interface{}, error) {
+func (c *Client) ChangePublicSettings(path string, settings *PublicSettings) (*PublicSettings, error) {
+	return c.ChangePublicSettingsContext(context.Background(), path, settings)
+}
+
+// ChangePublicSettingsContext validates settings, applies them to the
+// published resource at path and returns the settings from the response.
+func (c *Client) ChangePublicSettingsContext(ctx context.Context, path string, settings *PublicSettings) (*PublicSettings, error) {
+	if settings == nil {
+		return nil, fmt.Errorf("%w: settings are nil", ErrInvalidPublicSettings)
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
 	queryParams := url.Values{}
 	queryParams.Set("path", path)
 
-	data, err := c.request("PUT", "/resources/public", queryParams, settings)
+	data, err := c.request(ctx, "PUT", "/resources/public", queryParams, settings)
 	if err != nil {
 		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	var result PublicSettings
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+		}
 	}
 
-	return result, nil
+	return &result, nil
 }
 
 func (c *Client) UploadFromURL(fileURL, remotePath string, disableRedirects bool) (*Operation, error) {
+	return c.UploadFromURLContext(context.Background(), fileURL, remotePath, disableRedirects)
+}
+
+func (c *Client) UploadFromURLContext(ctx context.Context, fileURL, remotePath string, disableRedirects bool) (*Operation, error) {
 	queryParams := url.Values{}
 	queryParams.Set("url", fileURL)
 	queryParams.Set("path", remotePath)
@@ -572,7 +669,7 @@ func (c *Client) UploadFromURL(fileURL, remotePath string, disableRedirects bool
 		queryParams.Set("disable_redirects", "true")
 	}
 
-	data, err := c.request("POST", "/resources/upload", queryParams, nil)
+	data, err := c.request(ctx, "POST", "/resources/upload", queryParams, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -586,7 +683,11 @@ func (c *Client) UploadFromURL(fileURL, remotePath string, disableRedirects bool
 }
 
 func (c *Client) GetOperationStatus(operationID string) (*Operation, error) {
-	data, err := c.request("GET", "/operations/"+operationID, nil, nil)
+	return c.GetOperationStatusContext(context.Background(), operationID)
+}
+
+func (c *Client) GetOperationStatusContext(ctx context.Context, operationID string) (*Operation, error) {
+	data, err := c.request(ctx, "GET", "/operations/"+operationID, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -600,12 +701,16 @@ func (c *Client) GetOperationStatus(operationID string) (*Operation, error) {
 }
 
 func (c *Client) GetTrash(path string, limit, offset int) (*Resource, error) {
+	return c.GetTrashContext(context.Background(), path, limit, offset)
+}
+
+func (c *Client) GetTrashContext(ctx context.Context, path string, limit, offset int) (*Resource, error) {
 	queryParams := url.Values{}
 	queryParams.Set("path", path)
 	queryParams.Set("limit", fmt.Sprintf("%d", limit))
 	queryParams.Set("offset", fmt.Sprintf("%d", offset))
 
-	data, err := c.request("GET", "/trash/resources", queryParams, nil)
+	data, err := c.request(ctx, "GET", "/trash/resources", queryParams, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -619,6 +724,10 @@ func (c *Client) GetTrash(path string, limit, offset int) (*Resource, error) {
 }
 
 func (c *Client) RestoreFromTrash(path string, name *string, overwrite bool) (*Resource, error) {
+	return c.RestoreFromTrashContext(context.Background(), path, name, overwrite)
+}
+
+func (c *Client) RestoreFromTrashContext(ctx context.Context, path string, name *string, overwrite bool) (*Resource, error) {
 	queryParams := url.Values{}
 	queryParams.Set("path", path)
 	if name != nil {
@@ -628,7 +737,7 @@ func (c *Client) RestoreFromTrash(path string, name *string, overwrite bool) (*R
 		queryParams.Set("overwrite", "true")
 	}
 
-	data, err := c.request("PUT", "/trash/resources/restore", queryParams, nil)
+	data, err := c.request(ctx, "PUT", "/trash/resources/restore", queryParams, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -642,23 +751,44 @@ func (c *Client) RestoreFromTrash(path string, name *string, overwrite bool) (*R
 }
 
 func (c *Client) ClearTrash(path *string) error {
+	return c.ClearTrashContext(context.Background(), path)
+}
+
+// ClearTrashContext empties the Trash, or deletes path from it, waiting for
+// the operation to finish when the API works in the background.
+func (c *Client) ClearTrashContext(ctx context.Context, path *string) error {
+	op, err := c.ClearTrashAsync(ctx, path)
+	if err != nil || op == nil {
+		return err
+	}
+	_, err = c.WaitOperation(ctx, op, nil)
+	return err
+}
+
+// ClearTrashAsync starts emptying the Trash, or deleting path from it. It
+// returns the operation to pass to WaitOperation if the work continues in
+// the background, or nil if it has already completed.
+func (c *Client) ClearTrashAsync(ctx context.Context, path *string) (*Operation, error) {
 	queryParams := url.Values{}
 	if path != nil {
 		queryParams.Set("path", *path)
 	}
 
-	_, err := c.request("DELETE", "/trash/resources", queryParams, nil)
-	return err
+	return c.asyncRequest(ctx, "DELETE", "/trash/resources", queryParams)
 }
 
 func (c *Client) GetPublicResourcesOwnedByUser(userID, orgID string, limit, offset int) (*FilesList, error) {
+	return c.GetPublicResourcesOwnedByUserContext(context.Background(), userID, orgID, limit, offset)
+}
+
+func (c *Client) GetPublicResourcesOwnedByUserContext(ctx context.Context, userID, orgID string, limit, offset int) (*FilesList, error) {
 	queryParams := url.Values{}
 	queryParams.Set("user_id", userID)
 	queryParams.Set("org_id", orgID)
 	queryParams.Set("limit", fmt.Sprintf("%d", limit))
 	queryParams.Set("offset", fmt.Sprintf("%d", offset))
 
-	data, err := c.request("GET", "/public/resources/admin/public-resources", queryParams, nil)
+	data, err := c.request(ctx, "GET", "/public/resources/admin/public-resources", queryParams, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -672,6 +802,10 @@ func (c *Client) GetPublicResourcesOwnedByUser(userID, orgID string, limit, offs
 }
 
 func (c *Client) GetPublicResourcesAccessedByUser(userID, orgID string, includeGroupAccess bool, limit int, iterationKey *string) (*FilesList, error) {
+	return c.GetPublicResourcesAccessedByUserContext(context.Background(), userID, orgID, includeGroupAccess, limit, iterationKey)
+}
+
+func (c *Client) GetPublicResourcesAccessedByUserContext(ctx context.Context, userID, orgID string, includeGroupAccess bool, limit int, iterationKey *string) (*FilesList, error) {
 	queryParams := url.Values{}
 	queryParams.Set("user_id", userID)
 	queryParams.Set("org_id", orgID)
@@ -683,7 +817,7 @@ func (c *Client) GetPublicResourcesAccessedByUser(userID, orgID string, includeG
 		queryParams.Set("iteration_key", *iterationKey)
 	}
 
-	data, err := c.request("GET", "/public/resources/admin/accessible-resources", queryParams, nil)
+	data, err := c.request(ctx, "GET", "/public/resources/admin/accessible-resources", queryParams, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -697,11 +831,15 @@ func (c *Client) GetPublicResourcesAccessedByUser(userID, orgID string, includeG
 }
 
 func (c *Client) UnpublishUserResource(publicKey, userID, orgID string) error {
+	return c.UnpublishUserResourceContext(context.Background(), publicKey, userID, orgID)
+}
+
+func (c *Client) UnpublishUserResourceContext(ctx context.Context, publicKey, userID, orgID string) error {
 	queryParams := url.Values{}
 	queryParams.Set("public_key", publicKey)
 	queryParams.Set("user_id", userID)
 	queryParams.Set("org_id", orgID)
 
-	_, err := c.request("PUT", "/public/resources/admin/unpublish", queryParams, nil)
+	_, err := c.request(ctx, "PUT", "/public/resources/admin/unpublish", queryParams, nil)
 	return err
 }
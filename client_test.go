@@ -1,7 +1,12 @@
 package yandexdisk
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -20,10 +25,76 @@ func TestNewClient(t *testing.T) {
 	client := NewClient(token)
 
 	assert.NotNil(t, client)
-	assert.Equal(t, token, client.accessToken)
+	tok, err := client.tokenSource.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, token, tok.AccessToken)
 	assert.NotNil(t, client.httpClient)
 }
 
+// newBlockingServer answers metadata, download and operation requests but
+// blocks in the metadata handler, halfway through the download content and
+// in the operation status handler until the request is canceled. blocked
+// receives a value whenever a handler starts to block.
+func newBlockingServer(t *testing.T) (*httptest.Server, <-chan struct{}) {
+	blocked := make(chan struct{}, 1)
+	release := make(chan struct{})
+	block := func(r *http.Request) {
+		blocked <- struct{}{}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/resources/download":
+			w.Write([]byte(`{"href": "` + server.URL + `/content", "method": "GET"}`))
+		case "/content":
+			w.Header().Set("Content-Length", "1024")
+			w.Write(make([]byte, 512))
+			w.(http.Flusher).Flush()
+			block(r)
+		default:
+			block(r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+	return server, blocked
+}
+
+// cancelWhenBlocked returns a context that is canceled once the server
+// blocks.
+func cancelWhenBlocked(t *testing.T, blocked <-chan struct{}) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		select {
+		case <-blocked:
+		case <-time.After(5 * time.Second):
+		}
+		cancel()
+	}()
+	return ctx
+}
+
+func TestContextCancellation(t *testing.T) {
+	server, blocked := newBlockingServer(t)
+	client := NewClient("test-token", WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{}))
+
+	_, err := client.GetMetaContext(cancelWhenBlocked(t, blocked), "/disk/file.txt", nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	err = client.DownloadFileContext(cancelWhenBlocked(t, blocked), "/disk/file.txt", t.TempDir()+"/file.txt")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	op := &Operation{Href: server.URL + "/operations/abc123"}
+	_, err = client.WaitOperation(cancelWhenBlocked(t, blocked), op, &WaitOptions{Interval: time.Millisecond})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
 func TestDiskInfoGetFreeSpace(t *testing.T) {
 	diskInfo := &DiskInfo{
 		TotalSpace: 10000000000,
@@ -153,7 +224,7 @@ func TestAPIErrorError(t *testing.T) {
 	assert.Equal(t, "Test message", err2.Error())
 
 	err3 := &APIError{
-		Error:      "Test error",
+		ErrorCode:  "Test error",
 		StatusCode: 400,
 	}
 	assert.Equal(t, "Test error", err3.Error())
@@ -163,3 +234,32 @@ func TestAPIErrorError(t *testing.T) {
 	}
 	assert.Equal(t, "unknown API error", err4.Error())
 }
+
+func TestResourceJSON(t *testing.T) {
+	var resource Resource
+	err := json.Unmarshal([]byte(`{
+		"name": "photo.jpg",
+		"type": "file",
+		"media_type": "image",
+		"created": "2024-01-02T03:04:05+00:00",
+		"modified": "2024-01-02T06:04:05+03:00",
+		"photoslice_time": "2023-12-31T23:59:59+00:00",
+		"antivirus_status": "clean",
+		"exif": {"date_time": "2023-12-31T23:59:59+00:00", "gps_longitude": 37.6, "gps_latitude": 55.7},
+		"sizes": [{"url": "https://example.com/s", "name": "S"}]
+	}`), &resource)
+	assert.NoError(t, err)
+
+	assert.Equal(t, ResourceTypeFile, resource.Type)
+	assert.Equal(t, MediaTypeImage, resource.MediaType)
+	assert.True(t, resource.Created.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+	assert.True(t, resource.Modified.Equal(resource.Created))
+	assert.True(t, resource.PhotosliceTime.Equal(resource.Exif.DateTime))
+	assert.Equal(t, "clean", resource.AntivirusStatus)
+	assert.Equal(t, 55.7, resource.Exif.GPSLatitude)
+	assert.Equal(t, []PreviewSize{{URL: "https://example.com/s", Name: "S"}}, resource.Sizes)
+
+	var op Operation
+	assert.NoError(t, json.Unmarshal([]byte(`{"status": "success"}`), &op))
+	assert.Equal(t, OperationSuccess, op.Status)
+}
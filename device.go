@@ -0,0 +1,135 @@
+package yandexdisk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDeviceInterval = 5 * time.Second
+	defaultSlowDownStep   = 5 * time.Second
+)
+
+// DeviceAuth is the response to a device authorization request. Show
+// VerificationURL and UserCode to the user, then call DeviceAccessToken.
+type DeviceAuth struct {
+	DeviceCode      string
+	UserCode        string
+	VerificationURL string
+	// Interval is the minimum delay between token requests.
+	Interval time.Duration
+	// SlowDownStep is added to Interval whenever the server asks to slow
+	// down, 5 seconds if zero.
+	SlowDownStep time.Duration
+	// Expiry is when DeviceCode stops being valid.
+	Expiry time.Time
+}
+
+type deviceAuthResponse struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURL string `json:"verification_url"`
+	Interval        int64  `json:"interval"`
+	ExpiresIn       int64  `json:"expires_in"`
+}
+
+// DeviceAuth starts the device-code flow for tools without a browser. Use
+// SetAuthURLParam to pass "device_id" and "device_name".
+func (c *OAuthConfig) DeviceAuth(ctx context.Context, opts ...AuthCodeOption) (*DeviceAuth, error) {
+	params := url.Values{}
+	if len(c.Scopes) > 0 {
+		params.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	for _, opt := range opts {
+		opt(params)
+	}
+
+	deviceURL := c.DeviceAuthURL
+	if deviceURL == "" {
+		deviceURL = OAuthDeviceURL
+	}
+	data, err := c.post(ctx, deviceURL, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp deviceAuthResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal device code: %w", err)
+	}
+	if resp.DeviceCode == "" {
+		return nil, errors.New("oauth: server response has no device code")
+	}
+
+	auth := &DeviceAuth{
+		DeviceCode:      resp.DeviceCode,
+		UserCode:        resp.UserCode,
+		VerificationURL: resp.VerificationURL,
+		Interval:        time.Duration(resp.Interval) * time.Second,
+	}
+	if resp.ExpiresIn > 0 {
+		auth.Expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	return auth, nil
+}
+
+// DeviceAccessToken polls the token endpoint until the user confirms the
+// code, waiting longer whenever the server asks to slow down. It fails with
+// an *OAuthError once the user declines or the code expires.
+func (c *OAuthConfig) DeviceAccessToken(ctx context.Context, auth *DeviceAuth) (*Token, error) {
+	if !auth.Expiry.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, auth.Expiry)
+		defer cancel()
+	}
+
+	interval := auth.Interval
+	if interval <= 0 {
+		interval = defaultDeviceInterval
+	}
+	step := auth.SlowDownStep
+	if step <= 0 {
+		step = defaultSlowDownStep
+	}
+
+	params := url.Values{}
+	params.Set("grant_type", "device_code")
+	params.Set("code", auth.DeviceCode)
+
+	for {
+		if err := sleepContext(ctx, interval); err != nil {
+			return nil, auth.expiredError(err)
+		}
+
+		token, err := c.retrieveToken(ctx, params)
+		var oauthErr *OAuthError
+		if !errors.As(err, &oauthErr) {
+			if err != nil {
+				return nil, auth.expiredError(err)
+			}
+			return token, nil
+		}
+
+		switch oauthErr.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += step
+		default:
+			return nil, err
+		}
+	}
+}
+
+// expiredError replaces err with an expired_token error if it was caused by
+// reaching Expiry.
+func (a *DeviceAuth) expiredError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) && !a.Expiry.IsZero() && !time.Now().Before(a.Expiry) {
+		return &OAuthError{Code: "expired_token", Description: "device code expired"}
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package yandexdisk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newDeviceServer issues a device code and answers token requests with the
+// given OAuth error codes before returning a token.
+func newDeviceServer(t *testing.T, pending ...string) (*httptest.Server, *int32) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.URL.Path {
+		case "/device/code":
+			w.Write([]byte(`{"device_code": "dev-1", "user_code": "ABCD-1234", "verification_url": "https://ya.ru/device", "interval": 5, "expires_in": 300}`))
+		case "/token":
+			assert.Equal(t, "device_code", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "dev-1", r.PostForm.Get("code"))
+			n := int(atomic.AddInt32(&polls, 1))
+			if n <= len(pending) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error": "` + pending[n-1] + `"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token": "device-token", "refresh_token": "device-refresh", "expires_in": 3600}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &polls
+}
+
+func newDeviceConfig(server *httptest.Server) *OAuthConfig {
+	return &OAuthConfig{
+		ClientID:      "client",
+		ClientSecret:  "secret",
+		TokenURL:      server.URL + "/token",
+		DeviceAuthURL: server.URL + "/device/code",
+	}
+}
+
+func TestDeviceFlow(t *testing.T) {
+	server, polls := newDeviceServer(t, "authorization_pending", "slow_down", "authorization_pending")
+	config := newDeviceConfig(server)
+
+	auth, err := config.DeviceAuth(context.Background(), SetAuthURLParam("device_name", "backup-cron"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ABCD-1234", auth.UserCode)
+	assert.Equal(t, "https://ya.ru/device", auth.VerificationURL)
+	assert.Equal(t, 5*time.Second, auth.Interval)
+
+	auth.Interval, auth.SlowDownStep = time.Millisecond, time.Millisecond
+	token, err := config.DeviceAccessToken(context.Background(), auth)
+	assert.NoError(t, err)
+	assert.Equal(t, "device-token", token.AccessToken)
+	assert.Equal(t, int32(4), atomic.LoadInt32(polls))
+}
+
+func TestDeviceFlowDenied(t *testing.T) {
+	server, _ := newDeviceServer(t, "authorization_pending", "access_denied")
+	config := newDeviceConfig(server)
+
+	auth := &DeviceAuth{DeviceCode: "dev-1", Interval: time.Millisecond}
+	_, err := config.DeviceAccessToken(context.Background(), auth)
+	oauthErr, ok := err.(*OAuthError)
+	assert.True(t, ok)
+	assert.Equal(t, "access_denied", oauthErr.Code)
+}
+
+func TestDeviceFlowExpired(t *testing.T) {
+	server, _ := newDeviceServer(t, "authorization_pending", "authorization_pending", "authorization_pending")
+	config := newDeviceConfig(server)
+
+	auth := &DeviceAuth{DeviceCode: "dev-1", Interval: 10 * time.Millisecond, Expiry: time.Now().Add(15 * time.Millisecond)}
+	_, err := config.DeviceAccessToken(context.Background(), auth)
+	oauthErr, ok := err.(*OAuthError)
+	assert.True(t, ok)
+	assert.Equal(t, "expired_token", oauthErr.Code)
+}
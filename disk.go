@@ -0,0 +1,82 @@
+package yandexdisk
+
+import (
+	"context"
+	"io"
+)
+
+// MetaAPI covers resource metadata, folders and copy, move and delete.
+type MetaAPI interface {
+	GetCapacityContext(ctx context.Context) (*DiskInfo, error)
+	GetMetaContext(ctx context.Context, path string, params map[string]string) (*Resource, error)
+	AddMetaContext(ctx context.Context, path string, customProperties map[string]interface{}) (*Resource, error)
+	GetAllFilesContext(ctx context.Context, limit, offset int) (*FilesList, error)
+	GetRecentUploadsContext(ctx context.Context, limit, offset int) (*FilesList, error)
+	GetRecentPublishedContext(ctx context.Context, limit, offset int) (*FilesList, error)
+	CreateFolderContext(ctx context.Context, path string) (*Resource, error)
+	CopyContext(ctx context.Context, fromPath, toPath string, overwrite bool) (*Resource, error)
+	CopyAsync(ctx context.Context, fromPath, toPath string, overwrite bool) (*Operation, error)
+	MoveContext(ctx context.Context, fromPath, toPath string, overwrite bool) (*Resource, error)
+	MoveAsync(ctx context.Context, fromPath, toPath string, overwrite bool) (*Operation, error)
+	DeleteContext(ctx context.Context, path string, permanently bool) error
+	DeleteAsync(ctx context.Context, path string, permanently bool) (*Operation, error)
+	GetOperationStatusContext(ctx context.Context, operationID string) (*Operation, error)
+	WaitOperation(ctx context.Context, op *Operation, opts *WaitOptions) (*Operation, error)
+}
+
+// TransferAPI covers uploads and downloads of private files.
+type TransferAPI interface {
+	Upload(ctx context.Context, r io.Reader, remotePath string, opts *UploadOptions) (*UploadResult, error)
+	UploadFileContext(ctx context.Context, localFilePath, remotePath string, overwrite bool) (*UploadResult, error)
+	UploadFromURLContext(ctx context.Context, fileURL, remotePath string, disableRedirects bool) (*Operation, error)
+	Download(ctx context.Context, remotePath string) (io.ReadCloser, *Resource, error)
+	DownloadRange(ctx context.Context, remotePath string, offset, length int64) (io.ReadCloser, error)
+	DownloadTo(ctx context.Context, remotePath string, w io.Writer) (int64, error)
+	DownloadFileContext(ctx context.Context, remotePath, localPath string) error
+	DownloadFileParallel(ctx context.Context, remotePath, localPath string, opts *ParallelOptions) error
+}
+
+// TrashAPI covers the Trash.
+type TrashAPI interface {
+	GetTrashContext(ctx context.Context, path string, limit, offset int) (*Resource, error)
+	RestoreFromTrashContext(ctx context.Context, path string, name *string, overwrite bool) (*Resource, error)
+	ClearTrashContext(ctx context.Context, path *string) error
+	ClearTrashAsync(ctx context.Context, path *string) (*Operation, error)
+}
+
+// PublicAPI covers publishing and public resources.
+type PublicAPI interface {
+	PublishContext(ctx context.Context, path string) (*Resource, error)
+	UnpublishContext(ctx context.Context, path string) (*Resource, error)
+	GetPublicResourceMetaContext(ctx context.Context, publicKey string, params map[string]string) (*Resource, error)
+	DownloadPublic(ctx context.Context, publicKey string, path *string) (io.ReadCloser, error)
+	DownloadPublicTo(ctx context.Context, publicKey string, path *string, w io.Writer) (int64, error)
+	DownloadPublicResourceContext(ctx context.Context, publicKey, localPath string, path *string) error
+	SavePublicResourceContext(ctx context.Context, publicKey string, name, path *string) (*Resource, error)
+	GetAvailablePublicSettingsContext(ctx context.Context) (*AvailablePublicSettings, error)
+	GetPublicSettingsContext(ctx context.Context, path string, allowAddressAccess bool) (*PublicSettings, error)
+	ChangePublicSettingsContext(ctx context.Context, path string, settings *PublicSettings) (*PublicSettings, error)
+}
+
+// AdminAPI covers the organization administrator endpoints.
+type AdminAPI interface {
+	GetPublicResourcesOwnedByUserContext(ctx context.Context, userID, orgID string, limit, offset int) (*FilesList, error)
+	GetPublicResourcesAccessedByUserContext(ctx context.Context, userID, orgID string, includeGroupAccess bool, limit int, iterationKey *string) (*FilesList, error)
+	UnpublishUserResourceContext(ctx context.Context, publicKey, userID, orgID string) error
+}
+
+// Disk is the set of API calls implemented by *Client, for code that wants
+// to depend on an interface and substitute a mock in tests, such as
+// yandexdisktest.MockDisk. It contains the methods that take a context;
+// the variants without one are shorthands for them. The helpers built on top
+// of these calls are also available as functions of the interfaces: Walk,
+// NewFS, OpenRemoteFile and the Iter functions such as IterDir.
+type Disk interface {
+	MetaAPI
+	TransferAPI
+	TrashAPI
+	PublicAPI
+	AdminAPI
+}
+
+var _ Disk = (*Client)(nil)
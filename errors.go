@@ -0,0 +1,111 @@
+package yandexdisk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+)
+
+// Errors returned by the API can be tested with errors.Is against these
+// values. They are matched by status code and by the "error" field of the
+// response.
+var (
+	ErrNotFound            = errors.New("resource not found")
+	ErrAlreadyExists       = errors.New("resource already exists")
+	ErrInsufficientStorage = errors.New("insufficient storage")
+	ErrUnauthorized        = errors.New("unauthorized")
+	ErrTooManyRequests     = errors.New("too many requests")
+	ErrLocked              = errors.New("resource locked")
+)
+
+// requestIDHeaders are the response headers that carry the ID of a request,
+// in order of preference.
+var requestIDHeaders = []string{"Yandex-Cloud-Request-ID", "X-Request-Id"}
+
+var errorCodes = map[string]error{
+	"DiskNotFoundError":                      ErrNotFound,
+	"DiskPathDoesntExistsError":              ErrNotFound,
+	"DiskResourceAlreadyExistsError":         ErrAlreadyExists,
+	"DiskPathPointsToExistentDirectoryError": ErrAlreadyExists,
+	"DiskStorageQuotaExhaustedError":         ErrInsufficientStorage,
+	"UnauthorizedError":                      ErrUnauthorized,
+	"TooManyRequestsError":                   ErrTooManyRequests,
+	"DiskResourceLockedError":                ErrLocked,
+	"LockedError":                            ErrLocked,
+}
+
+// classifyError returns the sentinel error for an API error code or, if the
+// code is unknown, for a status code. It returns nil if neither matches.
+func classifyError(statusCode int, errorCode string) error {
+	if err, ok := errorCodes[errorCode]; ok {
+		return err
+	}
+
+	switch statusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrAlreadyExists
+	case http.StatusInsufficientStorage:
+		return ErrInsufficientStorage
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusTooManyRequests:
+		return ErrTooManyRequests
+	case http.StatusLocked:
+		return ErrLocked
+	}
+	return nil
+}
+
+func (e *APIError) Is(target error) bool {
+	return target != nil && target == classifyError(e.StatusCode, e.ErrorCode)
+}
+
+func (e *TransferError) Is(target error) bool {
+	return target != nil && target == classifyError(e.StatusCode, "")
+}
+
+func requestID(header http.Header) string {
+	for _, name := range requestIDHeaders {
+		if id := header.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// IsRetryable reports whether err is likely to be temporary, so that the same
+// request may succeed later: rate limiting, server errors and dropped
+// connections. Canceled requests are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.StatusCode)
+	}
+	var statusErr *TransferError
+	if errors.As(err, &statusErr) {
+		return isRetryableStatus(statusErr.StatusCode)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return !isPermanentNetError(err)
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
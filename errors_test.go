@@ -0,0 +1,70 @@
+package yandexdisk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIErrorIs(t *testing.T) {
+	err := fmt.Errorf("copy: %w", &APIError{StatusCode: 409, ErrorCode: "DiskResourceAlreadyExistsError"})
+	assert.ErrorIs(t, err, ErrAlreadyExists)
+	assert.NotErrorIs(t, err, ErrNotFound)
+
+	// The error code takes precedence over the status code.
+	err = &APIError{StatusCode: 409, ErrorCode: "DiskPathDoesntExistsError"}
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrAlreadyExists)
+
+	assert.ErrorIs(t, &APIError{StatusCode: 507}, ErrInsufficientStorage)
+	assert.ErrorIs(t, &APIError{StatusCode: 401}, ErrUnauthorized)
+	assert.ErrorIs(t, &APIError{StatusCode: 429}, ErrTooManyRequests)
+	assert.ErrorIs(t, &APIError{StatusCode: 423}, ErrLocked)
+	assert.NotErrorIs(t, &APIError{StatusCode: 400}, ErrNotFound)
+}
+
+func TestRequestErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Yandex-Cloud-Request-ID", "req-42")
+		if r.URL.Query().Get("path") == "/disk/html" {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html>Bad Gateway</html>"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Resource not found.", "description": "Resource not found.", "error": "DiskNotFoundError"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{}))
+
+	_, err := client.GetMeta("/disk/missing", nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.False(t, IsRetryable(err))
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "req-42", apiErr.RequestID)
+
+	_, err = client.GetMeta("/disk/html", nil)
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, "API request failed with status 502: <html>Bad Gateway</html>", apiErr.Error())
+	assert.True(t, IsRetryable(err))
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.False(t, IsRetryable(nil))
+	assert.False(t, IsRetryable(context.Canceled))
+	assert.False(t, IsRetryable(errors.New("boom")))
+	assert.True(t, IsRetryable(&APIError{StatusCode: 429}))
+	assert.True(t, IsRetryable(&TransferError{StatusCode: 503}))
+	assert.False(t, IsRetryable(&APIError{StatusCode: 507}))
+	assert.True(t, IsRetryable(fmt.Errorf("download failed: %w", io.ErrUnexpectedEOF)))
+}
@@ -0,0 +1,150 @@
+package yandexdisk
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fileServer serves a single file through the metadata, upload and download
+// endpoints and the hrefs they return, for the transfer tests. The fields
+// below content change how the next requests are answered.
+type fileServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	content []byte
+	// meta is the content whose checksums the metadata reports; it is
+	// content itself if nil.
+	meta []byte
+
+	// interrupts is the number of downloads cut off halfway.
+	interrupts int
+	// expiredURLs is the number of download hrefs that answer 410 Gone.
+	expiredURLs int
+	// failRange is a Range header answered once with 503.
+	failRange string
+	// ignoreRanges makes downloads always send the whole file.
+	ignoreRanges bool
+
+	links  int
+	ranges []string
+}
+
+func newFileServer(t *testing.T, content string) *fileServer {
+	s := &fileServer{content: []byte(content)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fileServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/resources":
+		s.mu.Lock()
+		meta := s.meta
+		if meta == nil {
+			meta = s.content
+		}
+		size := len(s.content)
+		s.mu.Unlock()
+
+		name := path.Base(r.URL.Query().Get("path"))
+		w.Write([]byte(fmt.Sprintf(`{"name": %q, "path": "disk:/%s", "type": "file", "size": %d, "md5": "%x", "sha256": "%x"}`,
+			name, name, size, md5.Sum(meta), sha256.Sum256(meta))))
+	case "/resources/upload":
+		w.Write([]byte(`{"href": "` + s.URL + `/content", "method": "PUT"}`))
+	case "/resources/download":
+		s.mu.Lock()
+		s.links++
+		href := "/content"
+		if s.links <= s.expiredURLs {
+			href = "/expired"
+		}
+		s.mu.Unlock()
+		w.Write([]byte(`{"href": "` + s.URL + href + `", "method": "GET"}`))
+	case "/public/resources/download":
+		w.Write([]byte(`{"href": "` + s.URL + `/public-content", "method": "GET"}`))
+	case "/expired":
+		w.WriteHeader(http.StatusGone)
+	case "/content":
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodPut {
+			data, _ := io.ReadAll(r.Body)
+			s.mu.Lock()
+			s.content, s.meta = data, nil
+			s.mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		s.serveContent(w, r)
+	case "/public-content":
+		s.serveContent(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *fileServer) serveContent(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	content := s.content
+	rng := r.Header.Get("Range")
+	s.ranges = append(s.ranges, rng)
+	fail := rng != "" && rng == s.failRange
+	if fail {
+		s.failRange = ""
+	}
+	interrupt := s.interrupts > 0
+	if interrupt {
+		s.interrupts--
+	}
+	ignoreRanges := s.ignoreRanges
+	s.mu.Unlock()
+
+	if fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("ETag", s.etag())
+	switch {
+	case interrupt:
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.Write(content[:len(content)/2])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	case ignoreRanges:
+		w.Write(content)
+	default:
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}
+}
+
+// requested reports whether a download asked for the given Range header.
+func (s *fileServer) requested(rng string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.ranges {
+		if r == rng {
+			return true
+		}
+	}
+	return false
+}
+
+// etag returns the ETag of the current content.
+func (s *fileServer) etag() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fmt.Sprintf(`"%x"`, md5.Sum(s.content))
+}
@@ -0,0 +1,295 @@
+package yandexdisk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// FS exposes the folder at root as a read-only fs.FS, so it can be passed to
+// fs.WalkDir, fs.Glob, template.ParseFS or http.FileServer(http.FS(...)).
+// All requests are made with the context given to Client.FS or NewFS.
+type FS struct {
+	disk Disk
+	ctx  context.Context
+	root string
+}
+
+var (
+	_ fs.FS         = (*FS)(nil)
+	_ fs.ReadDirFS  = (*FS)(nil)
+	_ fs.StatFS     = (*FS)(nil)
+	_ fs.ReadFileFS = (*FS)(nil)
+)
+
+// FS returns a file system rooted at the Disk folder root, such as
+// "disk:/Site" or "/Site".
+func (c *Client) FS(ctx context.Context, root string) *FS {
+	return NewFS(ctx, c, root)
+}
+
+// NewFS returns a file system rooted at the folder root on d.
+func NewFS(ctx context.Context, d Disk, root string) *FS {
+	return &FS{disk: d, ctx: ctx, root: root}
+}
+
+func (f *FS) resolve(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return path.Join(f.root, name), nil
+}
+
+// Open opens the named file or directory. Files are downloaded lazily and
+// support Seek, which reopens the download at the new offset.
+func (f *FS) Open(name string) (fs.File, error) {
+	remotePath, err := f.resolve("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := f.disk.GetMetaContext(f.ctx, remotePath, nil)
+	if err != nil {
+		return nil, fsError("open", name, err)
+	}
+
+	if resource.IsDir() {
+		return &dirFile{fsys: f, name: name, path: remotePath, resource: resource}, nil
+	}
+	return &fsFile{fsys: f, name: name, path: remotePath, resource: resource}, nil
+}
+
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	remotePath, err := f.resolve("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := f.disk.GetMetaContext(f.ctx, remotePath, nil)
+	if err != nil {
+		return nil, fsError("stat", name, err)
+	}
+	return &fileInfo{resource: resource}, nil
+}
+
+// ReadDir returns the entries of the named directory sorted by name.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	remotePath, err := f.resolve("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := f.readDir(remotePath)
+	if err != nil {
+		return nil, fsError("readdir", name, err)
+	}
+	return entries, nil
+}
+
+func (f *FS) readDir(remotePath string) ([]fs.DirEntry, error) {
+	it := IterDir(f.ctx, f.disk, remotePath, nil)
+	defer it.Close()
+
+	var entries []fs.DirEntry
+	for it.Next() {
+		resource := it.Resource()
+		entries = append(entries, fs.FileInfoToDirEntry(&fileInfo{resource: &resource}))
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	remotePath, err := f.resolve("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := f.disk.GetMetaContext(f.ctx, remotePath, nil)
+	if err != nil {
+		return nil, fsError("readfile", name, err)
+	}
+	if resource.IsDir() {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: errors.New("is a directory")}
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(int(resource.Size))
+	if _, err := f.disk.DownloadTo(f.ctx, remotePath, &buf); err != nil {
+		return nil, fsError("readfile", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fsError wraps err in an *fs.PathError, translating ErrNotFound to
+// fs.ErrNotExist.
+func fsError(op, name string, err error) error {
+	if errors.Is(err, ErrNotFound) {
+		err = fs.ErrNotExist
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// fileInfo describes a Resource as an fs.FileInfo. Sys returns the *Resource.
+type fileInfo struct {
+	resource *Resource
+}
+
+func (i *fileInfo) Name() string {
+	if i.resource.Name != "" {
+		return i.resource.Name
+	}
+	return path.Base(i.resource.Path)
+}
+
+func (i *fileInfo) Size() int64 {
+	return i.resource.Size
+}
+
+func (i *fileInfo) Mode() fs.FileMode {
+	if i.resource.IsDir() {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (i *fileInfo) ModTime() time.Time {
+	return i.resource.Modified
+}
+
+func (i *fileInfo) IsDir() bool {
+	return i.resource.IsDir()
+}
+
+func (i *fileInfo) Sys() any {
+	return i.resource
+}
+
+type dirFile struct {
+	fsys     *FS
+	name     string
+	path     string
+	resource *Resource
+	entries  []fs.DirEntry
+	read     bool
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) {
+	return &fileInfo{resource: d.resource}, nil
+}
+
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *dirFile) Close() error {
+	return nil
+}
+
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.fsys.readDir(d.path)
+		if err != nil {
+			return nil, fsError("readdir", d.name, err)
+		}
+		d.entries, d.read = entries, true
+	}
+
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+// fsFile reads a file with DownloadRange starting at its current offset.
+// Seeking closes the open download, if any.
+type fsFile struct {
+	fsys     *FS
+	name     string
+	path     string
+	resource *Resource
+	body     io.ReadCloser
+	offset   int64
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error) {
+	return &fileInfo{resource: f.resource}, nil
+}
+
+func (f *fsFile) Read(p []byte) (int, error) {
+	if f.offset >= f.resource.Size {
+		return 0, io.EOF
+	}
+	if f.body == nil {
+		if err := f.open(); err != nil {
+			return 0, &fs.PathError{Op: "read", Path: f.name, Err: err}
+		}
+	}
+
+	n, err := f.body.Read(p)
+	f.offset += int64(n)
+	if err != nil && err != io.EOF {
+		err = &fs.PathError{Op: "read", Path: f.name, Err: err}
+	}
+	return n, err
+}
+
+func (f *fsFile) open() error {
+	body, err := f.fsys.disk.DownloadRange(f.fsys.ctx, f.path, f.offset, -1)
+	if err != nil {
+		return err
+	}
+	f.body = body
+	return nil
+}
+
+func (f *fsFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.resource.Size
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fmt.Errorf("negative offset %d", offset)}
+	}
+
+	if offset != f.offset && f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *fsFile) Close() error {
+	if f.body == nil {
+		return nil
+	}
+	err := f.body.Close()
+	f.body = nil
+	return err
+}
@@ -0,0 +1,73 @@
+package yandexdisk_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tigusigalpa/yandex-disk-go/yandexdisktest"
+)
+
+var testSite = map[string]string{
+	"/site/index.html":       "<h1>Hello</h1>",
+	"/site/empty.txt":        "",
+	"/site/css/style.css":    "body { color: red; }",
+	"/site/img/logo/big.svg": "<svg/>",
+	"/other/secret.txt":      "not in the FS",
+}
+
+func newSiteServer(t *testing.T) *yandexdisktest.Server {
+	srv := yandexdisktest.NewServer()
+	t.Cleanup(srv.Close)
+	for p, content := range testSite {
+		srv.AddFile(p, []byte(content))
+	}
+	return srv
+}
+
+func TestFS(t *testing.T) {
+	fsys := newSiteServer(t).NewClient().FS(context.Background(), "/site")
+
+	err := fstest.TestFS(fsys, "index.html", "empty.txt", "css/style.css", "img/logo/big.svg")
+	assert.NoError(t, err)
+}
+
+func TestFSErrors(t *testing.T) {
+	fsys := newSiteServer(t).NewClient().FS(context.Background(), "/site")
+
+	_, err := fsys.Open("missing.txt")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	_, err = fs.Stat(fsys, "../other/secret.txt")
+	assert.True(t, errors.Is(err, fs.ErrInvalid))
+
+	_, err = fs.ReadFile(fsys, "css")
+	assert.Error(t, err)
+}
+
+func TestFSSeek(t *testing.T) {
+	fsys := newSiteServer(t).NewClient().FS(context.Background(), "/site")
+
+	f, err := fsys.Open("css/style.css")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	seeker := f.(io.ReadSeeker)
+	_, err = seeker.Seek(7, io.SeekStart)
+	assert.NoError(t, err)
+
+	data, err := io.ReadAll(seeker)
+	assert.NoError(t, err)
+	assert.Equal(t, "color: red; }", string(data))
+
+	info, err := f.Stat()
+	assert.NoError(t, err)
+	assert.Equal(t, "style.css", info.Name())
+	assert.Equal(t, int64(20), info.Size())
+	assert.False(t, info.ModTime().IsZero())
+	assert.False(t, info.IsDir())
+}
@@ -0,0 +1,262 @@
+package yandexdisk
+
+import (
+	"context"
+	"strconv"
+)
+
+const DefaultPageSize = 100
+
+type IterOptions struct {
+	// PageSize is the number of resources requested per page,
+	// DefaultPageSize if zero.
+	PageSize int
+	// Prefetch requests the next page in the background while the current
+	// one is being consumed.
+	Prefetch bool
+}
+
+// fetchPageFunc requests the page at offset. It returns the items and the
+// total number of resources, or -1 if the endpoint does not report it.
+type fetchPageFunc func(ctx context.Context, limit, offset int) ([]Resource, int, error)
+
+type page struct {
+	items []Resource
+	total int
+	err   error
+}
+
+// ResourceIterator pages through a list endpoint. Call Next until it returns
+// false, then check Err:
+//
+//	it := client.IterAllFiles(ctx, nil)
+//	defer it.Close()
+//	for it.Next() {
+//		fmt.Println(it.Resource().Path)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type ResourceIterator struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	fetch    fetchPageFunc
+	pageSize int
+	prefetch bool
+	// cursor is set for endpoints that may return short pages before the
+	// end; the iteration then only ends at the reported total.
+	cursor bool
+
+	items   []Resource
+	current Resource
+	offset  int
+	last    bool
+	pending chan page
+	err     error
+}
+
+func newResourceIterator(ctx context.Context, opts *IterOptions, fetch fetchPageFunc) *ResourceIterator {
+	ctx, cancel := context.WithCancel(ctx)
+	it := &ResourceIterator{
+		ctx:      ctx,
+		cancel:   cancel,
+		fetch:    fetch,
+		pageSize: DefaultPageSize,
+	}
+	if opts != nil {
+		if opts.PageSize > 0 {
+			it.pageSize = opts.PageSize
+		}
+		it.prefetch = opts.Prefetch
+	}
+	return it
+}
+
+// Next advances to the next resource, fetching another page when the current
+// one is exhausted. It returns false at the end of the list or on error.
+func (it *ResourceIterator) Next() bool {
+	for len(it.items) == 0 {
+		if it.last || it.err != nil {
+			return false
+		}
+		p := it.nextPage()
+		if p.err != nil {
+			it.err = p.err
+			it.Close()
+			return false
+		}
+
+		it.items = p.items
+		it.offset += len(p.items)
+		it.last = (!it.cursor && len(p.items) < it.pageSize) || (p.total >= 0 && it.offset >= p.total)
+		if !it.last && it.prefetch {
+			it.pending = make(chan page, 1)
+			go func(ch chan<- page, offset int) {
+				ch <- it.load(offset)
+			}(it.pending, it.offset)
+		}
+	}
+
+	it.current, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+func (it *ResourceIterator) nextPage() page {
+	if it.pending != nil {
+		ch := it.pending
+		it.pending = nil
+		return <-ch
+	}
+	return it.load(it.offset)
+}
+
+func (it *ResourceIterator) load(offset int) page {
+	items, total, err := it.fetch(it.ctx, it.pageSize, offset)
+	return page{items: items, total: total, err: err}
+}
+
+// Resource returns the resource Next advanced to.
+func (it *ResourceIterator) Resource() Resource {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ResourceIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iteration and cancels a pending prefetch. It is safe to
+// call Close more than once.
+func (it *ResourceIterator) Close() {
+	it.cancel()
+	it.items = nil
+	it.last = true
+}
+
+// IterAllFiles iterates over GetAllFiles.
+func (c *Client) IterAllFiles(ctx context.Context, opts *IterOptions) *ResourceIterator {
+	return IterAllFiles(ctx, c, opts)
+}
+
+// IterAllFiles iterates over the GetAllFiles listing of d.
+func IterAllFiles(ctx context.Context, d MetaAPI, opts *IterOptions) *ResourceIterator {
+	return newResourceIterator(ctx, opts, func(ctx context.Context, limit, offset int) ([]Resource, int, error) {
+		list, err := d.GetAllFilesContext(ctx, limit, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		return list.Items, -1, nil
+	})
+}
+
+// IterRecentUploads iterates over GetRecentUploads.
+func (c *Client) IterRecentUploads(ctx context.Context, opts *IterOptions) *ResourceIterator {
+	return IterRecentUploads(ctx, c, opts)
+}
+
+// IterRecentUploads iterates over the GetRecentUploads listing of d.
+func IterRecentUploads(ctx context.Context, d MetaAPI, opts *IterOptions) *ResourceIterator {
+	return newResourceIterator(ctx, opts, func(ctx context.Context, limit, offset int) ([]Resource, int, error) {
+		list, err := d.GetRecentUploadsContext(ctx, limit, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		return list.Items, -1, nil
+	})
+}
+
+// IterRecentPublished iterates over GetRecentPublished.
+func (c *Client) IterRecentPublished(ctx context.Context, opts *IterOptions) *ResourceIterator {
+	return IterRecentPublished(ctx, c, opts)
+}
+
+// IterRecentPublished iterates over the GetRecentPublished listing of d.
+func IterRecentPublished(ctx context.Context, d MetaAPI, opts *IterOptions) *ResourceIterator {
+	return newResourceIterator(ctx, opts, func(ctx context.Context, limit, offset int) ([]Resource, int, error) {
+		list, err := d.GetRecentPublishedContext(ctx, limit, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		return list.Items, -1, nil
+	})
+}
+
+// IterPublicResourcesOwnedByUser iterates over GetPublicResourcesOwnedByUser.
+func (c *Client) IterPublicResourcesOwnedByUser(ctx context.Context, userID, orgID string, opts *IterOptions) *ResourceIterator {
+	return IterPublicResourcesOwnedByUser(ctx, c, userID, orgID, opts)
+}
+
+// IterPublicResourcesOwnedByUser iterates over the
+// GetPublicResourcesOwnedByUser listing of d.
+func IterPublicResourcesOwnedByUser(ctx context.Context, d AdminAPI, userID, orgID string, opts *IterOptions) *ResourceIterator {
+	return newResourceIterator(ctx, opts, func(ctx context.Context, limit, offset int) ([]Resource, int, error) {
+		list, err := d.GetPublicResourcesOwnedByUserContext(ctx, userID, orgID, limit, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		return list.Items, -1, nil
+	})
+}
+
+// IterPublicResourcesAccessedByUser iterates over
+// GetPublicResourcesAccessedByUser, following its iteration_key cursor.
+func (c *Client) IterPublicResourcesAccessedByUser(ctx context.Context, userID, orgID string, includeGroupAccess bool, opts *IterOptions) *ResourceIterator {
+	return IterPublicResourcesAccessedByUser(ctx, c, userID, orgID, includeGroupAccess, opts)
+}
+
+// IterPublicResourcesAccessedByUser iterates over the
+// GetPublicResourcesAccessedByUser listing of d, following its iteration_key
+// cursor.
+func IterPublicResourcesAccessedByUser(ctx context.Context, d AdminAPI, userID, orgID string, includeGroupAccess bool, opts *IterOptions) *ResourceIterator {
+	// Pages are requested one after another, so the cursor returned with a
+	// page is known before the next one is fetched.
+	var key *string
+	it := newResourceIterator(ctx, opts, func(ctx context.Context, limit, offset int) ([]Resource, int, error) {
+		list, err := d.GetPublicResourcesAccessedByUserContext(ctx, userID, orgID, includeGroupAccess, limit, key)
+		if err != nil {
+			return nil, 0, err
+		}
+		if list.IterationKey == "" || (key != nil && list.IterationKey == *key) {
+			return list.Items, offset + len(list.Items), nil
+		}
+		key = &list.IterationKey
+		return list.Items, -1, nil
+	})
+	it.cursor = true
+	return it
+}
+
+// IterDir iterates over the entries of the directory at path.
+func (c *Client) IterDir(ctx context.Context, path string, opts *IterOptions) *ResourceIterator {
+	return IterDir(ctx, c, path, opts)
+}
+
+// IterDir iterates over the entries of the directory at path on d.
+func IterDir(ctx context.Context, d MetaAPI, path string, opts *IterOptions) *ResourceIterator {
+	return newResourceIterator(ctx, opts, func(ctx context.Context, limit, offset int) ([]Resource, int, error) {
+		dir, err := d.GetMetaContext(ctx, path, map[string]string{
+			"limit":  strconv.Itoa(limit),
+			"offset": strconv.Itoa(offset),
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		return dir.GetItems(), dir.GetTotalItems(), nil
+	})
+}
+
+// IterTrash iterates over the entries of the Trash folder at path.
+func (c *Client) IterTrash(ctx context.Context, path string, opts *IterOptions) *ResourceIterator {
+	return IterTrash(ctx, c, path, opts)
+}
+
+// IterTrash iterates over the entries of the Trash folder at path on d.
+func IterTrash(ctx context.Context, d TrashAPI, path string, opts *IterOptions) *ResourceIterator {
+	return newResourceIterator(ctx, opts, func(ctx context.Context, limit, offset int) ([]Resource, int, error) {
+		dir, err := d.GetTrashContext(ctx, path, limit, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		return dir.GetItems(), dir.GetTotalItems(), nil
+	})
+}
@@ -0,0 +1,111 @@
+package yandexdisk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pageServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	offsets []string
+}
+
+// newPageServer lists count files at /resources/files, as the entries of the
+// directory at /resources and, by iteration_key, as the resources accessible
+// to a user.
+func newPageServer(t *testing.T, count int) *pageServer {
+	s := &pageServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		cursor := r.URL.Path == "/public/resources/admin/accessible-resources"
+		if cursor {
+			// Pages are keyed by the offset and hold at most three items.
+			offset, _ = strconv.Atoi(r.URL.Query().Get("iteration_key"))
+			limit = min(limit, 3)
+		}
+		s.mu.Lock()
+		s.offsets = append(s.offsets, r.URL.Query().Get("offset")+r.URL.Query().Get("iteration_key"))
+		s.mu.Unlock()
+
+		items := []Resource{}
+		for i := offset; i < count && i < offset+limit; i++ {
+			items = append(items, Resource{Name: "file" + strconv.Itoa(i)})
+		}
+
+		switch r.URL.Path {
+		case "/resources/files":
+			json.NewEncoder(w).Encode(FilesList{Items: items, Limit: limit, Offset: offset})
+		case "/resources":
+			json.NewEncoder(w).Encode(Resource{Type: "dir", Embedded: &Embedded{Items: items, Limit: limit, Offset: offset, Total: count}})
+		case "/public/resources/admin/accessible-resources":
+			list := FilesList{Items: items, Limit: limit}
+			if offset+limit < count {
+				list.IterationKey = strconv.Itoa(offset + limit)
+			}
+			json.NewEncoder(w).Encode(list)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func collect(t *testing.T, it *ResourceIterator) []string {
+	defer it.Close()
+	var names []string
+	for it.Next() {
+		names = append(names, it.Resource().Name)
+	}
+	assert.NoError(t, it.Err())
+	return names
+}
+
+func TestIterAllFiles(t *testing.T) {
+	server := newPageServer(t, 5)
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	names := collect(t, client.IterAllFiles(context.Background(), &IterOptions{PageSize: 2}))
+	assert.Equal(t, []string{"file0", "file1", "file2", "file3", "file4"}, names)
+	assert.Equal(t, []string{"0", "2", "4"}, server.offsets)
+}
+
+func TestIterDirStopsAtTotal(t *testing.T) {
+	server := newPageServer(t, 4)
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	names := collect(t, client.IterDir(context.Background(), "/disk/folder", &IterOptions{PageSize: 2, Prefetch: true}))
+	assert.Len(t, names, 4)
+	assert.Equal(t, []string{"0", "2"}, server.offsets)
+}
+
+func TestIterPublicResourcesAccessedByUser(t *testing.T) {
+	server := newPageServer(t, 8)
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	it := client.IterPublicResourcesAccessedByUser(context.Background(), "user", "org", false, &IterOptions{PageSize: 5, Prefetch: true})
+	assert.Len(t, collect(t, it), 8)
+	assert.Equal(t, []string{"", "3", "6"}, server.offsets)
+}
+
+func TestIterPropagatesError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message": "Unauthorized", "error": "UnauthorizedError"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", WithBaseURL(server.URL))
+	it := client.IterTrash(context.Background(), "/", nil)
+	assert.False(t, it.Next())
+	assert.Error(t, it.Err())
+}
@@ -1,5 +1,7 @@
 package yandexdisk
 
+import "time"
+
 type DiskInfo struct {
 	TotalSpace                  int64             `json:"total_space"`
 	UsedSpace                   int64             `json:"used_space"`
@@ -31,15 +33,48 @@ type User struct {
 	UID         string `json:"uid"`
 }
 
+// ResourceType is the type of a Resource.
+type ResourceType string
+
+const (
+	ResourceTypeDir  ResourceType = "dir"
+	ResourceTypeFile ResourceType = "file"
+)
+
+// MediaType is the kind of content of a file, as detected by Yandex Disk.
+type MediaType string
+
+const (
+	MediaTypeAudio       MediaType = "audio"
+	MediaTypeBackup      MediaType = "backup"
+	MediaTypeBook        MediaType = "book"
+	MediaTypeCompressed  MediaType = "compressed"
+	MediaTypeData        MediaType = "data"
+	MediaTypeDevelopment MediaType = "development"
+	MediaTypeDiskImage   MediaType = "diskimage"
+	MediaTypeDocument    MediaType = "document"
+	MediaTypeEncoded     MediaType = "encoded"
+	MediaTypeExecutable  MediaType = "executable"
+	MediaTypeFlash       MediaType = "flash"
+	MediaTypeFont        MediaType = "font"
+	MediaTypeImage       MediaType = "image"
+	MediaTypeSettings    MediaType = "settings"
+	MediaTypeSpreadsheet MediaType = "spreadsheet"
+	MediaTypeText        MediaType = "text"
+	MediaTypeUnknown     MediaType = "unknown"
+	MediaTypeVideo       MediaType = "video"
+	MediaTypeWeb         MediaType = "web"
+)
+
 type Resource struct {
 	Name             string                 `json:"name"`
 	Path             string                 `json:"path"`
-	Type             string                 `json:"type"`
+	Type             ResourceType           `json:"type"`
 	Size             int64                  `json:"size"`
-	Created          string                 `json:"created"`
-	Modified         string                 `json:"modified"`
+	Created          time.Time              `json:"created"`
+	Modified         time.Time              `json:"modified"`
 	MimeType         string                 `json:"mime_type"`
-	MediaType        string                 `json:"media_type"`
+	MediaType        MediaType              `json:"media_type"`
 	Preview          string                 `json:"preview"`
 	File             string                 `json:"file"`
 	MD5              string                 `json:"md5"`
@@ -52,14 +87,38 @@ type Resource struct {
 	Owner            Owner                  `json:"owner"`
 	Embedded         *Embedded              `json:"_embedded,omitempty"`
 	Revision         int64                  `json:"revision"`
+	// AntivirusStatus is the result of the antivirus check of a file, such
+	// as "clean" or "infected".
+	AntivirusStatus string `json:"antivirus_status"`
+	// Exif holds the photo metadata of image files.
+	Exif Exif `json:"exif"`
+	// Sizes lists the preview variants of the file.
+	Sizes []PreviewSize `json:"sizes"`
+	// PhotosliceTime is when the photo was taken, as used by the
+	// Photoslice. It is zero for other files.
+	PhotosliceTime time.Time `json:"photoslice_time"`
+}
+
+// Exif is the photo metadata of an image file.
+type Exif struct {
+	DateTime     time.Time `json:"date_time"`
+	GPSLongitude float64   `json:"gps_longitude"`
+	GPSLatitude  float64   `json:"gps_latitude"`
+}
+
+// PreviewSize is a preview variant of a file. Name is the size, such as
+// "S", "XL" or "ORIGINAL".
+type PreviewSize struct {
+	URL  string `json:"url"`
+	Name string `json:"name"`
 }
 
 func (r *Resource) IsDir() bool {
-	return r.Type == "dir"
+	return r.Type == ResourceTypeDir
 }
 
 func (r *Resource) IsFile() bool {
-	return r.Type == "file"
+	return r.Type == ResourceTypeFile
 }
 
 func (r *Resource) IsPublished() bool {
@@ -105,6 +164,15 @@ type FilesList struct {
 	Limit  int        `json:"limit"`
 	Offset int        `json:"offset"`
 	Total  int        `json:"total"`
+	// IterationKey is the cursor of the next page of
+	// GetPublicResourcesAccessedByUser, empty on the last page.
+	IterationKey string `json:"iteration_key,omitempty"`
+}
+
+type Link struct {
+	Href      string `json:"href"`
+	Method    string `json:"method"`
+	Templated bool   `json:"templated"`
 }
 
 type UploadResult struct {
@@ -112,31 +180,43 @@ type UploadResult struct {
 	Success bool
 }
 
+// OperationStatus is the state of an asynchronous operation.
+type OperationStatus string
+
+const (
+	OperationInProgress OperationStatus = "in-progress"
+	OperationSuccess    OperationStatus = "success"
+	OperationFailed     OperationStatus = "failed"
+)
+
 type Operation struct {
-	Status    string `json:"status"`
-	Type      string `json:"type"`
-	Href      string `json:"href"`
-	Method    string `json:"method"`
-	Templated bool   `json:"templated"`
+	Status    OperationStatus `json:"status"`
+	Type      string          `json:"type"`
+	Href      string          `json:"href"`
+	Method    string          `json:"method"`
+	Templated bool            `json:"templated"`
 }
 
 func (o *Operation) IsInProgress() bool {
-	return o.Status == "in-progress"
+	return o.Status == OperationInProgress
 }
 
 func (o *Operation) IsSuccess() bool {
-	return o.Status == "success"
+	return o.Status == OperationSuccess
 }
 
 func (o *Operation) IsFailed() bool {
-	return o.Status == "failed"
+	return o.Status == OperationFailed
 }
 
 type APIError struct {
 	Message     string `json:"message"`
 	Description string `json:"description"`
-	Error       string `json:"error"`
+	ErrorCode   string `json:"error"`
 	StatusCode  int
+	// RequestID identifies the failed request in the API logs, if the
+	// response carried one.
+	RequestID string
 }
 
 func (e *APIError) Error() string {
@@ -146,8 +226,8 @@ func (e *APIError) Error() string {
 	if e.Message != "" {
 		return e.Message
 	}
-	if e.Error != "" {
-		return e.Error
+	if e.ErrorCode != "" {
+		return e.ErrorCode
 	}
 	return "unknown API error"
 }
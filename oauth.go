@@ -0,0 +1,263 @@
+package yandexdisk
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	OAuthAuthURL   = "https://oauth.yandex.ru/authorize"
+	OAuthTokenURL  = "https://oauth.yandex.ru/token"
+	OAuthRevokeURL = "https://oauth.yandex.ru/revoke_token"
+	OAuthDeviceURL = "https://oauth.yandex.ru/device/code"
+)
+
+// expiryDelta is subtracted from the token lifetime so that a token is
+// refreshed slightly before the server rejects it.
+const expiryDelta = 10 * time.Second
+
+// OAuthConfig describes an application registered at oauth.yandex.ru and
+// implements the authorization-code flow with optional PKCE.
+type OAuthConfig struct {
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback URL registered for the application. It may
+	// be empty if only one is registered.
+	RedirectURL string
+	// Scopes limits the requested permissions, for example
+	// "cloud_api:disk.read". Empty means the scopes of the application.
+	Scopes []string
+
+	// AuthURL, TokenURL, RevokeURL and DeviceAuthURL override the Yandex
+	// OAuth endpoints, which is mainly useful in tests.
+	AuthURL       string
+	TokenURL      string
+	RevokeURL     string
+	DeviceAuthURL string
+	// HTTPClient is used for token requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+// Token is an OAuth token issued by Yandex.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// Valid reports whether the token has an access token that has not expired.
+// A zero Expiry means the token does not expire.
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(t.Expiry)
+}
+
+// OAuthError is returned when the OAuth server rejects a request.
+type OAuthError struct {
+	StatusCode  int
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("oauth: %s: %s", e.Code, e.Description)
+	}
+	if e.Code != "" {
+		return "oauth: " + e.Code
+	}
+	return fmt.Sprintf("oauth: request failed with status %d", e.StatusCode)
+}
+
+// AuthCodeOption adds parameters to an authorization URL or a token request.
+type AuthCodeOption func(url.Values)
+
+// S256ChallengeOption adds the PKCE challenge derived from verifier to an
+// authorization URL. Pass the same verifier to Exchange with VerifierOption.
+func S256ChallengeOption(verifier string) AuthCodeOption {
+	sum := sha256.Sum256([]byte(verifier))
+	return func(v url.Values) {
+		v.Set("code_challenge", base64.RawURLEncoding.EncodeToString(sum[:]))
+		v.Set("code_challenge_method", "S256")
+	}
+}
+
+// VerifierOption adds the PKCE code verifier to a code exchange.
+func VerifierOption(verifier string) AuthCodeOption {
+	return func(v url.Values) {
+		v.Set("code_verifier", verifier)
+	}
+}
+
+// SetAuthURLParam sets an arbitrary parameter, such as "login_hint" or
+// "force_confirm".
+func SetAuthURLParam(key, value string) AuthCodeOption {
+	return func(v url.Values) {
+		v.Set(key, value)
+	}
+}
+
+// GenerateVerifier returns a random PKCE code verifier.
+func GenerateVerifier() string {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// AuthCodeURL returns the URL to send the user to. state is echoed back to
+// the redirect URL and should be checked there to prevent CSRF.
+func (c *OAuthConfig) AuthCodeURL(state string, opts ...AuthCodeOption) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", c.ClientID)
+	if c.RedirectURL != "" {
+		params.Set("redirect_uri", c.RedirectURL)
+	}
+	if len(c.Scopes) > 0 {
+		params.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+	for _, opt := range opts {
+		opt(params)
+	}
+
+	authURL := c.AuthURL
+	if authURL == "" {
+		authURL = OAuthAuthURL
+	}
+	return authURL + "?" + params.Encode()
+}
+
+// Exchange trades the authorization code received at the redirect URL for a
+// token.
+func (c *OAuthConfig) Exchange(ctx context.Context, code string, opts ...AuthCodeOption) (*Token, error) {
+	params := url.Values{}
+	params.Set("grant_type", "authorization_code")
+	params.Set("code", code)
+	for _, opt := range opts {
+		opt(params)
+	}
+	return c.retrieveToken(ctx, params)
+}
+
+// Refresh obtains a new token using a refresh token. If the response does
+// not include a new refresh token, the old one is kept.
+func (c *OAuthConfig) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	params := url.Values{}
+	params.Set("grant_type", "refresh_token")
+	params.Set("refresh_token", refreshToken)
+
+	token, err := c.retrieveToken(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	return token, nil
+}
+
+// Revoke invalidates an access token.
+func (c *OAuthConfig) Revoke(ctx context.Context, accessToken string) error {
+	params := url.Values{}
+	params.Set("access_token", accessToken)
+
+	revokeURL := c.RevokeURL
+	if revokeURL == "" {
+		revokeURL = OAuthRevokeURL
+	}
+	_, err := c.post(ctx, revokeURL, params)
+	return err
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+func (c *OAuthConfig) retrieveToken(ctx context.Context, params url.Values) (*Token, error) {
+	tokenURL := c.TokenURL
+	if tokenURL == "" {
+		tokenURL = OAuthTokenURL
+	}
+
+	data, err := c.post(ctx, tokenURL, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp tokenResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token: %w", err)
+	}
+	if resp.AccessToken == "" {
+		return nil, fmt.Errorf("oauth: server response has no access token")
+	}
+
+	token := &Token{
+		AccessToken:  resp.AccessToken,
+		TokenType:    resp.TokenType,
+		RefreshToken: resp.RefreshToken,
+	}
+	if resp.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// post sends a form with the client credentials and returns the body of a
+// successful response.
+func (c *OAuthConfig) post(ctx context.Context, endpoint string, params url.Values) ([]byte, error) {
+	params.Set("client_id", c.ClientID)
+	if c.ClientSecret != "" {
+		params.Set("client_secret", c.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		oauthErr := &OAuthError{}
+		json.Unmarshal(data, oauthErr)
+		oauthErr.StatusCode = resp.StatusCode
+		return nil, oauthErr
+	}
+	return data, nil
+}
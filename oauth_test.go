@@ -0,0 +1,118 @@
+package yandexdisk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newOAuthServer(t *testing.T) (*httptest.Server, *url.Values) {
+	var last url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		last = r.PostForm
+		if r.PostForm.Get("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": "invalid_client", "error_description": "Client not found"}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/token":
+			switch r.PostForm.Get("grant_type") {
+			case "authorization_code":
+				if r.PostForm.Get("code") != "good-code" {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"error": "invalid_grant", "error_description": "Code has expired"}`))
+					return
+				}
+				w.Write([]byte(`{"access_token": "access-1", "refresh_token": "refresh-1", "token_type": "bearer", "expires_in": 3600}`))
+			case "refresh_token":
+				w.Write([]byte(`{"access_token": "access-2", "token_type": "bearer", "expires_in": 3600}`))
+			}
+		case "/revoke_token":
+			w.Write([]byte(`{"status": "ok"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &last
+}
+
+func newTestOAuthConfig(server *httptest.Server) *OAuthConfig {
+	return &OAuthConfig{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		TokenURL:     server.URL + "/token",
+		RevokeURL:    server.URL + "/revoke_token",
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	config := &OAuthConfig{ClientID: "client", RedirectURL: "https://example.com/cb", Scopes: []string{"cloud_api:disk.read", "cloud_api:disk.write"}}
+	verifier := GenerateVerifier()
+
+	u, err := url.Parse(config.AuthCodeURL("xyz", S256ChallengeOption(verifier)))
+	assert.NoError(t, err)
+	assert.Equal(t, "oauth.yandex.ru", u.Host)
+
+	q := u.Query()
+	assert.Equal(t, "code", q.Get("response_type"))
+	assert.Equal(t, "client", q.Get("client_id"))
+	assert.Equal(t, "https://example.com/cb", q.Get("redirect_uri"))
+	assert.Equal(t, "cloud_api:disk.read cloud_api:disk.write", q.Get("scope"))
+	assert.Equal(t, "xyz", q.Get("state"))
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+
+	sum := sha256.Sum256([]byte(verifier))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(sum[:]), q.Get("code_challenge"))
+}
+
+func TestOAuthExchangeAndRefresh(t *testing.T) {
+	server, last := newOAuthServer(t)
+	config := newTestOAuthConfig(server)
+
+	token, err := config.Exchange(context.Background(), "good-code", VerifierOption("verifier"))
+	assert.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken)
+	assert.Equal(t, "refresh-1", token.RefreshToken)
+	assert.True(t, token.Valid())
+	assert.WithinDuration(t, time.Now().Add(time.Hour), token.Expiry, time.Minute)
+	assert.Equal(t, "verifier", last.Get("code_verifier"))
+
+	token, err = config.Refresh(context.Background(), token.RefreshToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "access-2", token.AccessToken)
+	assert.Equal(t, "refresh-1", token.RefreshToken)
+
+	assert.NoError(t, config.Revoke(context.Background(), token.AccessToken))
+	assert.Equal(t, "access-2", last.Get("access_token"))
+}
+
+func TestOAuthError(t *testing.T) {
+	server, _ := newOAuthServer(t)
+	config := newTestOAuthConfig(server)
+
+	_, err := config.Exchange(context.Background(), "bad-code")
+	assert.EqualError(t, err, "oauth: invalid_grant: Code has expired")
+
+	config.ClientSecret = "wrong"
+	_, err = config.Refresh(context.Background(), "refresh-1")
+	oauthErr, ok := err.(*OAuthError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, oauthErr.StatusCode)
+	assert.Equal(t, "invalid_client", oauthErr.Code)
+}
+
+func TestTokenValid(t *testing.T) {
+	assert.False(t, (*Token)(nil).Valid())
+	assert.False(t, (&Token{}).Valid())
+	assert.True(t, (&Token{AccessToken: "a"}).Valid())
+	assert.False(t, (&Token{AccessToken: "a", Expiry: time.Now().Add(time.Second)}).Valid())
+}
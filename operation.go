@@ -0,0 +1,148 @@
+package yandexdisk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPollInterval    = time.Second
+	DefaultMaxPollInterval = 10 * time.Second
+)
+
+var ErrOperationFailed = errors.New("operation failed")
+
+// OperationFailedError is returned by WaitOperation when the status of an
+// operation becomes "failed". It matches ErrOperationFailed.
+type OperationFailedError struct {
+	ID string
+}
+
+func (e *OperationFailedError) Error() string {
+	return fmt.Sprintf("operation %s failed", e.ID)
+}
+
+func (e *OperationFailedError) Is(target error) bool {
+	return target == ErrOperationFailed
+}
+
+type WaitOptions struct {
+	// Interval is the delay before the first status check,
+	// DefaultPollInterval if zero.
+	Interval time.Duration
+	// Multiplier grows the interval after every check. Values below 1 keep
+	// it constant.
+	Multiplier float64
+	// MaxInterval caps the grown interval, DefaultMaxPollInterval if zero.
+	MaxInterval time.Duration
+	// Timeout bounds the whole wait. Zero means the wait is limited only by
+	// the context.
+	Timeout time.Duration
+}
+
+// ID returns the operation ID, the last element of the status URL in Href,
+// or "" if Href is not an operation URL.
+func (o *Operation) ID() string {
+	u, err := url.Parse(o.Href)
+	if err != nil {
+		return ""
+	}
+	i := strings.LastIndex(u.Path, "/operations/")
+	if i < 0 {
+		return ""
+	}
+	return strings.Trim(u.Path[i+len("/operations/"):], "/")
+}
+
+// WithWaitOptions sets the options WaitOperation uses when it is given nil,
+// including when Copy, Move, Delete and ClearTrash wait for an operation.
+func WithWaitOptions(opts WaitOptions) Option {
+	return func(c *Client) {
+		c.waitOptions = opts
+	}
+}
+
+// WaitOperation polls the status of op until it completes. It returns the
+// final status, or an *OperationFailedError if the operation failed. A nil
+// opts uses the options set by WithWaitOptions.
+func (c *Client) WaitOperation(ctx context.Context, op *Operation, opts *WaitOptions) (*Operation, error) {
+	if opts == nil {
+		opts = &c.waitOptions
+	}
+
+	id := op.ID()
+	if id == "" {
+		return nil, fmt.Errorf("invalid operation link: %q", op.Href)
+	}
+
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	maxInterval := opts.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = DefaultMaxPollInterval
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	status := op
+	for {
+		switch {
+		case status.IsSuccess():
+			return status, nil
+		case status.IsFailed():
+			return status, &OperationFailedError{ID: id}
+		}
+
+		if err := sleepContext(ctx, interval); err != nil {
+			return status, err
+		}
+		if opts.Multiplier > 1 {
+			interval = min(time.Duration(float64(interval)*opts.Multiplier), maxInterval)
+		}
+
+		var err error
+		if status, err = c.GetOperationStatusContext(ctx, id); err != nil {
+			return nil, err
+		}
+		if status.Href == "" {
+			status.Href = op.Href
+		}
+	}
+}
+
+// asyncRequest makes a request the API may complete in the background. It
+// returns the operation for 202 Accepted responses and nil otherwise.
+func (c *Client) asyncRequest(ctx context.Context, method, endpoint string, queryParams url.Values) (*Operation, error) {
+	data, status, err := c.requestStatus(ctx, method, endpoint, queryParams, nil)
+	if err != nil || status != http.StatusAccepted {
+		return nil, err
+	}
+
+	var operation Operation
+	if err := json.Unmarshal(data, &operation); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal operation: %w", err)
+	}
+
+	return &operation, nil
+}
+
+// waitResource waits for op, if any, and returns the resource at path.
+func (c *Client) waitResource(ctx context.Context, op *Operation, path string) (*Resource, error) {
+	if op != nil {
+		if _, err := c.WaitOperation(ctx, op, nil); err != nil {
+			return nil, err
+		}
+	}
+	return c.GetMetaContext(ctx, path, nil)
+}
@@ -0,0 +1,141 @@
+package yandexdisk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newOperationServer reports the operation as in progress for the first
+// pending polls and then with the final status.
+func newOperationServer(t *testing.T, pending int32, final string) (*httptest.Server, *int32) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/operations/abc123" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if atomic.AddInt32(&polls, 1) <= pending {
+			w.Write([]byte(`{"status": "in-progress"}`))
+			return
+		}
+		w.Write([]byte(`{"status": "` + final + `"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &polls
+}
+
+func TestOperationID(t *testing.T) {
+	op := &Operation{Href: "https://cloud-api.yandex.net/v1/disk/operations/abc123"}
+	assert.Equal(t, "abc123", op.ID())
+
+	op = &Operation{Href: "https://cloud-api.yandex.net/v1/disk/resources?path=disk%3A%2Ffile"}
+	assert.Equal(t, "", op.ID())
+}
+
+func TestWaitOperation(t *testing.T) {
+	server, polls := newOperationServer(t, 2, "success")
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	op := &Operation{Href: server.URL + "/operations/abc123"}
+	status, err := client.WaitOperation(context.Background(), op, &WaitOptions{Interval: time.Millisecond, Multiplier: 2})
+
+	assert.NoError(t, err)
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, op.Href, status.Href)
+	assert.Equal(t, int32(3), atomic.LoadInt32(polls))
+}
+
+func TestWaitOperationFailed(t *testing.T) {
+	server, _ := newOperationServer(t, 0, "failed")
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	op := &Operation{Href: server.URL + "/operations/abc123"}
+	_, err := client.WaitOperation(context.Background(), op, &WaitOptions{Interval: time.Millisecond})
+
+	assert.ErrorIs(t, err, ErrOperationFailed)
+	assert.EqualError(t, err, "operation abc123 failed")
+}
+
+func TestWaitOperationTimeout(t *testing.T) {
+	server, _ := newOperationServer(t, 1000, "success")
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	op := &Operation{Href: server.URL + "/operations/abc123"}
+	_, err := client.WaitOperation(context.Background(), op, &WaitOptions{Interval: time.Millisecond, Timeout: 20 * time.Millisecond})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// newAsyncServer answers copy, move and delete requests with 202 Accepted and
+// reports the operation as successful on the second poll.
+func newAsyncServer(t *testing.T) (*httptest.Server, *int32) {
+	var polls int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/operations/op1":
+			if atomic.AddInt32(&polls, 1) == 1 {
+				w.Write([]byte(`{"status": "in-progress"}`))
+				return
+			}
+			w.Write([]byte(`{"status": "success"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/resources":
+			w.Write([]byte(`{"name": "b", "path": "disk:/b", "type": "dir"}`))
+		default:
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"href": "` + server.URL + `/operations/op1", "method": "GET", "templated": false}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &polls
+}
+
+func TestCopyWaitsForOperation(t *testing.T) {
+	server, polls := newAsyncServer(t)
+	client := NewClient("test-token", WithBaseURL(server.URL), WithWaitOptions(WaitOptions{Interval: time.Millisecond}))
+
+	resource, err := client.Copy("/disk/a", "/disk/b", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "disk:/b", resource.Path)
+	assert.Equal(t, int32(2), atomic.LoadInt32(polls))
+}
+
+func TestDeleteAsync(t *testing.T) {
+	server, polls := newAsyncServer(t)
+	client := NewClient("test-token", WithBaseURL(server.URL), WithWaitOptions(WaitOptions{Interval: time.Millisecond}))
+
+	op, err := client.DeleteAsync(context.Background(), "/disk/b", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "op1", op.ID())
+	assert.Equal(t, int32(0), atomic.LoadInt32(polls))
+
+	assert.NoError(t, client.Delete("/disk/b", true))
+	assert.Equal(t, int32(2), atomic.LoadInt32(polls))
+}
+
+func TestMoveCompletedSynchronously(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"href": "https://cloud-api.yandex.net/v1/disk/resources?path=disk%3A%2Fb", "method": "GET"}`))
+			return
+		}
+		w.Write([]byte(`{"name": "b", "path": "disk:/b", "type": "file"}`))
+	}))
+	defer server.Close()
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	op, err := client.MoveAsync(context.Background(), "/disk/a", "/disk/b", false)
+	assert.NoError(t, err)
+	assert.Nil(t, op)
+
+	resource, err := client.Move("/disk/a", "/disk/b", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", resource.Name)
+}
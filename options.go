@@ -0,0 +1,112 @@
+package yandexdisk
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTimeout   = 30 * time.Second
+	DefaultUserAgent = "yandex-disk-go"
+)
+
+// Option configures a Client created by NewClient.
+type Option func(*Client)
+
+// WithBaseURL overrides the REST API base URL, e.g. to point the client at a
+// local stand-in of the API.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient makes the client send all requests through hc. The client
+// is copied, so later options never modify the caller's value.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc == nil {
+			return
+		}
+		clone := *hc
+		c.httpClient = &clone
+	}
+}
+
+// WithTransport sets the RoundTripper used for all requests.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = rt
+	}
+}
+
+// WithTimeout limits the duration of a single API call. Zero disables the
+// limit. Upload and download transfers are governed by WithTransferTimeout.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithTransferTimeout limits the duration of a single upload or download
+// transfer. By default transfers are only bounded by the caller's context.
+func WithTransferTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.transferTimeout = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request. It
+// defaults to DefaultUserAgent.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithHeader adds a header that is sent with every request.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.headers.Add(key, value)
+	}
+}
+
+// WithInsecureSkipVerify disables TLS certificate verification. It is meant
+// for local testing only; verification is enabled by default.
+//
+// The option clones the client's *http.Transport (http.DefaultTransport if
+// none is set) and has no effect on any other RoundTripper given with
+// WithTransport or WithHTTPClient, such as a recorder or a wrapping
+// middleware. Configure TLS on such a transport directly.
+func WithInsecureSkipVerify() Option {
+	return func(c *Client) {
+		c.insecureSkipVerify = true
+	}
+}
+
+func (c *Client) initHTTPClient() {
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{}
+	}
+	if c.transport != nil {
+		c.httpClient.Transport = c.transport
+	}
+	if !c.insecureSkipVerify {
+		return
+	}
+
+	base := c.httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t, ok := base.(*http.Transport); ok {
+		t = t.Clone()
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.InsecureSkipVerify = true
+		c.httpClient.Transport = t
+	}
+}
@@ -0,0 +1,82 @@
+package yandexdisk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClientDefaults(t *testing.T) {
+	client := NewClient("test-token")
+
+	assert.Equal(t, APIBaseURL, client.baseURL)
+	assert.Equal(t, DefaultUserAgent, client.userAgent)
+	assert.Equal(t, DefaultTimeout, client.timeout)
+	assert.Nil(t, client.httpClient.Transport)
+}
+
+func TestNewClientOptions(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Write([]byte(`{"total_space": 100, "used_space": 40}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token",
+		WithBaseURL(server.URL+"/v1/disk/"),
+		WithUserAgent("my-app/1.0"),
+		WithHeader("X-Request-Source", "tests"),
+	)
+
+	diskInfo, err := client.GetCapacity()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(60), diskInfo.GetFreeSpace())
+	assert.Equal(t, "/v1/disk/", got.URL.Path)
+	assert.Equal(t, "my-app/1.0", got.Header.Get("User-Agent"))
+	assert.Equal(t, "tests", got.Header.Get("X-Request-Source"))
+	assert.Equal(t, "OAuth test-token", got.Header.Get("Authorization"))
+}
+
+func TestWithHTTPClientDoesNotModifyCaller(t *testing.T) {
+	hc := &http.Client{Timeout: time.Minute}
+	rt := &http.Transport{}
+
+	client := NewClient("test-token", WithHTTPClient(hc), WithTransport(rt))
+
+	assert.Equal(t, rt, client.httpClient.Transport)
+	assert.Equal(t, time.Minute, client.httpClient.Timeout)
+	assert.Nil(t, hc.Transport)
+}
+
+func TestTLSVerification(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", WithBaseURL(server.URL))
+	_, err := client.GetCapacity()
+	assert.Error(t, err)
+
+	client = NewClient("test-token", WithBaseURL(server.URL), WithInsecureSkipVerify())
+	_, err = client.GetCapacity()
+	assert.NoError(t, err)
+}
+
+func TestWithTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", WithBaseURL(server.URL), WithTimeout(20*time.Millisecond))
+	_, err := client.GetCapacity()
+	assert.Error(t, err)
+}
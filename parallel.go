@@ -0,0 +1,269 @@
+package yandexdisk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	DefaultChunkSize   = 8 << 20
+	DefaultParallelism = 4
+)
+
+type ParallelOptions struct {
+	// ChunkSize is the size of each Range request, DefaultChunkSize if zero.
+	ChunkSize int64
+	// Parallelism is the number of concurrent requests, DefaultParallelism
+	// if zero.
+	Parallelism int
+}
+
+// DownloadFileParallel downloads remotePath into localPath by fetching chunks
+// of the file concurrently. Small files and servers that do not support
+// Range requests fall back to DownloadFileContext. The progress callback, if
+// any, may be called from several chunk workers at once.
+func (c *Client) DownloadFileParallel(ctx context.Context, remotePath, localPath string, opts *ParallelOptions) error {
+	chunkSize, parallelism := int64(DefaultChunkSize), DefaultParallelism
+	if opts != nil && opts.ChunkSize > 0 {
+		chunkSize = opts.ChunkSize
+	}
+	if opts != nil && opts.Parallelism > 0 {
+		parallelism = opts.Parallelism
+	}
+
+	resource, err := c.GetMetaContext(ctx, remotePath, nil)
+	if err != nil {
+		return err
+	}
+	if parallelism < 2 || resource.Size <= chunkSize {
+		return c.DownloadFileContext(ctx, remotePath, localPath)
+	}
+
+	link, err := c.getDownloadLink(ctx, remotePath)
+	if err != nil {
+		return err
+	}
+
+	d := &parallelDownload{
+		client:     c,
+		remotePath: remotePath,
+		href:       link.Href,
+		size:       resource.Size,
+		chunkSize:  chunkSize,
+	}
+	if fn := c.progressFunc(ctx); fn != nil {
+		d.progress = newProgressTracker(resource.Size, 0, fn)
+	}
+
+	// Chunks report progress through d, not individually.
+	quiet := ContextWithProgress(ctx, nil)
+	first, err := c.openRange(quiet, d.href, true, 0, chunkSize)
+	if err != nil {
+		return err
+	}
+	if !first.Partial || first.Size != d.size {
+		first.Close()
+		return c.DownloadFileContext(ctx, remotePath, localPath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		first.Close()
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	partPath := localPath + partialSuffix
+	d.file, err = os.Create(partPath)
+	if err != nil {
+		first.Close()
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+
+	if err := d.run(quiet, first, parallelism); err != nil {
+		d.file.Close()
+		os.Remove(partPath)
+		return err
+	}
+
+	if err := d.file.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if c.verifyChecksums {
+		if err := verifyFile(partPath, resource); err != nil {
+			os.Remove(partPath)
+			return err
+		}
+	}
+	if err := os.Rename(partPath, localPath); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+type parallelDownload struct {
+	client     *Client
+	remotePath string
+	size       int64
+	chunkSize  int64
+	file       *os.File
+
+	mu       sync.Mutex
+	href     string
+	progress *progressTracker
+}
+
+// run fetches all chunks with parallelism workers. first is the already
+// opened body of the chunk at offset 0.
+func (d *parallelDownload) run(ctx context.Context, first *rangeBody, parallelism int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	offsets := make(chan int64)
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func(first *rangeBody) {
+			defer wg.Done()
+			if first != nil {
+				if err := d.fetchChunk(ctx, 0, first); err != nil {
+					fail(err)
+					return
+				}
+			}
+			for offset := range offsets {
+				if err := d.fetchChunk(ctx, offset, nil); err != nil {
+					fail(err)
+					return
+				}
+			}
+		}(first)
+		first = nil
+	}
+
+produce:
+	for offset := d.chunkSize; offset < d.size; offset += d.chunkSize {
+		select {
+		case offsets <- offset:
+		case <-ctx.Done():
+			break produce
+		}
+	}
+	close(offsets)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// fetchChunk writes the chunk starting at offset, resuming it when the
+// transfer breaks and requesting a fresh href when the current one expired.
+func (d *parallelDownload) fetchChunk(ctx context.Context, offset int64, body *rangeBody) error {
+	c := d.client
+	length := min(d.chunkSize, d.size-offset)
+	var written int64
+
+	for attempt := 0; ; attempt++ {
+		err := d.copyChunk(ctx, offset+written, length-written, body, &written)
+		body = nil
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= c.maxResumes || ctx.Err() != nil {
+			return err
+		}
+
+		var statusErr *TransferError
+		if errors.As(err, &statusErr) && isExpiredLinkStatus(statusErr.StatusCode) {
+			if err := d.refreshLink(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := sleepContext(ctx, c.retryPolicy.backoff(attempt+1)); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *parallelDownload) copyChunk(ctx context.Context, offset, length int64, body *rangeBody, written *int64) error {
+	if body == nil {
+		d.mu.Lock()
+		href := d.href
+		d.mu.Unlock()
+
+		var err error
+		body, err = d.client.openRange(ctx, href, true, offset, length)
+		if err != nil {
+			return err
+		}
+	}
+	defer body.Close()
+
+	if !body.Partial || body.Offset != offset {
+		return fmt.Errorf("download failed: server did not honor range request at offset %d", offset)
+	}
+
+	w := &chunkWriter{d: d, w: io.NewOffsetWriter(d.file, offset)}
+	n, err := io.Copy(w, io.LimitReader(body, length))
+	*written += n
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	if n < length {
+		return fmt.Errorf("download failed: %w", io.ErrUnexpectedEOF)
+	}
+	return nil
+}
+
+func (d *parallelDownload) refreshLink(ctx context.Context) error {
+	link, err := d.client.getDownloadLink(ctx, d.remotePath)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.href = link.Href
+	d.mu.Unlock()
+	return nil
+}
+
+// chunkWriter writes a chunk into the file and reports the aggregated
+// progress of all chunks.
+type chunkWriter struct {
+	d *parallelDownload
+	w io.Writer
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if w.d.progress != nil && n > 0 {
+		w.d.mu.Lock()
+		progress, ok := w.d.progress.record(int64(n), false)
+		w.d.mu.Unlock()
+		// The callback runs unlocked so that a slow one does not stall the
+		// other chunks.
+		if ok {
+			w.d.progress.fn(progress)
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,101 @@
+package yandexdisk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadFileParallel(t *testing.T) {
+	content := strings.Repeat("abcdefghij", 10)
+	server := newFileServer(t, content)
+	server.failRange = "bytes=48-63"
+
+	var (
+		mu   sync.Mutex
+		last Progress
+	)
+	client := NewClient("test-token", WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{}), WithProgress(func(p Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		if p.Transferred > last.Transferred {
+			last = p
+		}
+	}))
+
+	localPath := filepath.Join(t.TempDir(), "file.bin")
+	err := client.DownloadFileParallel(context.Background(), "/disk/file.bin", localPath, &ParallelOptions{ChunkSize: 16, Parallelism: 3})
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(localPath)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(data))
+	assert.NoFileExists(t, localPath+partialSuffix)
+	assert.Len(t, server.ranges, 8)
+	assert.Contains(t, server.ranges, "bytes=96-99")
+	assert.Equal(t, int64(100), last.Transferred)
+}
+
+func TestDownloadFileParallelSlowProgress(t *testing.T) {
+	content := strings.Repeat("abcdefghij", 10)
+	server := newFileServer(t, content)
+
+	// The first report blocks until the other workers have requested the
+	// last chunk, which they cannot do if reporting stalls them.
+	var (
+		once    sync.Once
+		unblock bool
+	)
+	client := NewClient("test-token", WithBaseURL(server.URL), WithProgress(func(p Progress) {
+		once.Do(func() {
+			deadline := time.Now().Add(5 * time.Second)
+			for !unblock && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+				unblock = server.requested("bytes=96-99")
+			}
+		})
+	}))
+
+	localPath := filepath.Join(t.TempDir(), "file.bin")
+	err := client.DownloadFileParallel(context.Background(), "/disk/file.bin", localPath, &ParallelOptions{ChunkSize: 16, Parallelism: 3})
+	assert.NoError(t, err)
+	assert.True(t, unblock, "a blocked progress callback stalled the other chunks")
+
+	data, _ := os.ReadFile(localPath)
+	assert.Equal(t, content, string(data))
+}
+
+func TestDownloadFileParallelFallsBackWithoutRanges(t *testing.T) {
+	content := strings.Repeat("abcdefghij", 10)
+	server := newFileServer(t, content)
+	server.ignoreRanges = true
+
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	localPath := filepath.Join(t.TempDir(), "file.bin")
+	err := client.DownloadFileParallel(context.Background(), "/disk/file.bin", localPath, &ParallelOptions{ChunkSize: 16})
+	assert.NoError(t, err)
+
+	data, _ := os.ReadFile(localPath)
+	assert.Equal(t, content, string(data))
+	assert.Equal(t, []string{"bytes=0-15", ""}, server.ranges)
+}
+
+func TestDownloadFileParallelSmallFile(t *testing.T) {
+	server := newFileServer(t, "small")
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	localPath := filepath.Join(t.TempDir(), "file.bin")
+	err := client.DownloadFileParallel(context.Background(), "/disk/file.bin", localPath, nil)
+	assert.NoError(t, err)
+
+	data, _ := os.ReadFile(localPath)
+	assert.Equal(t, "small", string(data))
+	assert.Equal(t, []string{""}, server.ranges)
+}
@@ -0,0 +1,143 @@
+package yandexdisk
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// progressInterval is the minimal delay between two progress reports of a
+// transfer. The report for the last chunk is always delivered.
+const progressInterval = 100 * time.Millisecond
+
+// Progress describes the state of an upload or download.
+type Progress struct {
+	Transferred int64
+	// Total is the size of the transfer, or -1 if it is not known.
+	Total int64
+	// Rate is the average speed in bytes per second.
+	Rate    float64
+	Elapsed time.Duration
+	// ETA is the estimated remaining time, zero when Total is unknown.
+	ETA time.Duration
+}
+
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return float64(p.Transferred) / float64(p.Total) * 100
+}
+
+type ProgressFunc func(Progress)
+
+// WithProgress reports the progress of every upload and download made by the
+// client. A function attached with ContextWithProgress takes precedence.
+func WithProgress(fn ProgressFunc) Option {
+	return func(c *Client) {
+		c.progress = fn
+	}
+}
+
+type progressKey struct{}
+
+// ContextWithProgress attaches a progress callback to a single call; a nil
+// fn disables the client-wide callback for that call. For example:
+//
+//	ctx := yandexdisk.ContextWithProgress(ctx, func(p yandexdisk.Progress) {
+//		fmt.Printf("\r%.1f%%", p.Percent())
+//	})
+//	client.UploadFileContext(ctx, "big.iso", "/disk/big.iso", true)
+func ContextWithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+func (c *Client) progressFunc(ctx context.Context) ProgressFunc {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok {
+		return fn
+	}
+	return c.progress
+}
+
+// progressTracker turns byte counts into Progress reports.
+type progressTracker struct {
+	fn       ProgressFunc
+	total    int64
+	offset   int64
+	read     int64
+	start    time.Time
+	reported time.Time
+	finished bool
+}
+
+// newProgressTracker returns a tracker for a transfer of total bytes, of
+// which offset were transferred earlier, e.g. before a resume.
+func newProgressTracker(total, offset int64, fn ProgressFunc) *progressTracker {
+	return &progressTracker{fn: fn, total: total, offset: offset, start: time.Now()}
+}
+
+// add records n more bytes and reports them; eof marks the end of the
+// transfer.
+func (p *progressTracker) add(n int64, eof bool) {
+	if progress, ok := p.record(n, eof); ok {
+		p.fn(progress)
+	}
+}
+
+// record counts n more bytes and returns the report that is due, if any,
+// without calling fn, so that callers holding a lock can report after
+// releasing it.
+func (p *progressTracker) record(n int64, eof bool) (Progress, bool) {
+	p.read += n
+	if p.finished {
+		return Progress{}, false
+	}
+
+	now := time.Now()
+	done := eof || (p.total >= 0 && p.offset+p.read >= p.total)
+	if done || (n > 0 && now.Sub(p.reported) >= progressInterval) {
+		p.finished = done
+		p.reported = now
+		return p.progress(now), true
+	}
+	return Progress{}, false
+}
+
+func (p *progressTracker) progress(now time.Time) Progress {
+	progress := Progress{
+		Transferred: p.offset + p.read,
+		Total:       p.total,
+		Elapsed:     now.Sub(p.start),
+	}
+	if seconds := progress.Elapsed.Seconds(); seconds > 0 {
+		progress.Rate = float64(p.read) / seconds
+	}
+	if p.total >= 0 && progress.Rate > 0 {
+		remaining := p.total - progress.Transferred
+		if remaining > 0 {
+			progress.ETA = time.Duration(float64(remaining) / progress.Rate * float64(time.Second))
+		}
+	}
+	return progress
+}
+
+type progressReader struct {
+	r       io.Reader
+	tracker *progressTracker
+}
+
+// newProgressReader wraps r so that fn is called as data flows through it.
+// offset is the number of bytes transferred before r, e.g. when resuming.
+// It returns r unchanged when fn is nil.
+func newProgressReader(r io.Reader, total, offset int64, fn ProgressFunc) io.Reader {
+	if fn == nil {
+		return r
+	}
+	return &progressReader{r: r, tracker: newProgressTracker(total, offset, fn)}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.tracker.add(int64(n), err == io.EOF)
+	return n, err
+}
@@ -0,0 +1,79 @@
+package yandexdisk
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressReader(t *testing.T) {
+	var reports []Progress
+	r := newProgressReader(strings.NewReader("0123456789"), 10, 0, func(p Progress) {
+		reports = append(reports, p)
+	})
+
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789", string(data))
+
+	last := reports[len(reports)-1]
+	assert.Equal(t, int64(10), last.Transferred)
+	assert.Equal(t, int64(10), last.Total)
+	assert.Equal(t, 100.0, last.Percent())
+	assert.Zero(t, last.ETA)
+}
+
+func TestProgressReaderOffset(t *testing.T) {
+	var last Progress
+	r := newProgressReader(strings.NewReader("6789"), 10, 6, func(p Progress) {
+		last = p
+	})
+
+	io.ReadAll(r)
+	assert.Equal(t, int64(10), last.Transferred)
+}
+
+func TestProgressReaderNilFunc(t *testing.T) {
+	src := strings.NewReader("data")
+	assert.Same(t, src, newProgressReader(src, 4, 0, nil))
+}
+
+func TestProgressPercentUnknownTotal(t *testing.T) {
+	assert.Equal(t, 0.0, Progress{Transferred: 5, Total: -1}.Percent())
+}
+
+func TestUploadProgressFromContext(t *testing.T) {
+	rec := newUploadServer(t)
+	client := NewClient("test-token", WithBaseURL(rec.server.URL))
+
+	var last Progress
+	ctx := ContextWithProgress(context.Background(), func(p Progress) {
+		last = p
+	})
+
+	_, err := client.Upload(ctx, strings.NewReader("hello world"), "/disk/file.txt", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), last.Transferred)
+	assert.Equal(t, int64(11), last.Total)
+}
+
+func TestDownloadProgressFromClient(t *testing.T) {
+	server := newFileServer(t, "file content")
+
+	var last Progress
+	client := NewClient("test-token", WithBaseURL(server.URL), WithProgress(func(p Progress) {
+		last = p
+	}))
+
+	var buf bytes.Buffer
+	_, err := client.DownloadTo(context.Background(), "/disk/file.txt", &buf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), last.Transferred)
+	assert.Equal(t, int64(12), last.Total)
+}
@@ -0,0 +1,264 @@
+package yandexdisk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidPublicSettings is returned by PublicSettings.Validate and by
+// ChangePublicSettings for settings the API would reject.
+var ErrInvalidPublicSettings = errors.New("invalid public settings")
+
+// AccessType is the kind of subject an Access entry grants rights to.
+type AccessType string
+
+const (
+	AccessTypeMacro      AccessType = "macro"
+	AccessTypeUser       AccessType = "user"
+	AccessTypeGroup      AccessType = "group"
+	AccessTypeDepartment AccessType = "department"
+)
+
+// AccessMacro selects a predefined group of users for AccessTypeMacro.
+type AccessMacro string
+
+const (
+	// AccessMacroAll is anyone with the link.
+	AccessMacroAll AccessMacro = "all"
+	// AccessMacroEmployees is every employee of the organization.
+	AccessMacroEmployees AccessMacro = "employees"
+)
+
+// AccessRight is an access mode granted by an Access entry.
+type AccessRight string
+
+const (
+	AccessRightRead                            AccessRight = "read"
+	AccessRightWrite                           AccessRight = "write"
+	AccessRightReadWithoutDownload             AccessRight = "read_without_download"
+	AccessRightReadWithPassword                AccessRight = "read_with_password"
+	AccessRightReadWithPasswordWithoutDownload AccessRight = "read_with_password_without_download"
+)
+
+// Access grants rights to a user, group, department or macro.
+type Access struct {
+	Type AccessType `json:"type"`
+	// Macros is set for AccessTypeMacro.
+	Macros []AccessMacro `json:"macros,omitempty"`
+	// ID identifies the user, group or department.
+	ID     string        `json:"id,omitempty"`
+	OrgID  string        `json:"org_id,omitempty"`
+	Rights []AccessRight `json:"rights"`
+}
+
+// PublicSettings are the access settings of a published resource.
+type PublicSettings struct {
+	// AvailableUntil is when the public link expires, zero if never.
+	AvailableUntil time.Time
+	// ReadOnly forbids changes through the public link. It is nil if the
+	// API did not report it, and a nil ReadOnly leaves the setting
+	// unchanged.
+	ReadOnly *bool
+	// Password protects the public link. It is only sent, never returned
+	// by the API.
+	Password               string
+	ExternalOrganizationID string
+	Accesses               []Access
+
+	// RemoveExpiry and RemovePassword make ChangePublicSettings clear the
+	// expiry and the password of the link, which a zero AvailableUntil or
+	// an empty Password leave unchanged.
+	RemoveExpiry   bool
+	RemovePassword bool
+
+	// Raw holds the fields of the API response that have no typed
+	// counterpart. They are sent back unchanged by ChangePublicSettings.
+	Raw map[string]json.RawMessage
+}
+
+var publicSettingsFields = []string{"available_until", "read_only", "password", "external_organization_id", "accesses"}
+
+// Validate checks the settings before they are sent to the API.
+func (s *PublicSettings) Validate() error {
+	if s.RemoveExpiry && !s.AvailableUntil.IsZero() {
+		return fmt.Errorf("%w: both available_until and RemoveExpiry are set", ErrInvalidPublicSettings)
+	}
+	if s.RemovePassword && s.Password != "" {
+		return fmt.Errorf("%w: both password and RemovePassword are set", ErrInvalidPublicSettings)
+	}
+	if !s.AvailableUntil.IsZero() && !s.AvailableUntil.After(time.Now()) {
+		return fmt.Errorf("%w: available_until %s is in the past", ErrInvalidPublicSettings, s.AvailableUntil.Format(time.RFC3339))
+	}
+
+	for i, access := range s.Accesses {
+		switch access.Type {
+		case AccessTypeMacro:
+			if len(access.Macros) == 0 {
+				return fmt.Errorf("%w: access %d: macro access needs macros", ErrInvalidPublicSettings, i)
+			}
+			for _, macro := range access.Macros {
+				if macro != AccessMacroAll && macro != AccessMacroEmployees {
+					return fmt.Errorf("%w: access %d: unknown macro %q", ErrInvalidPublicSettings, i, macro)
+				}
+			}
+		case AccessTypeUser, AccessTypeGroup, AccessTypeDepartment:
+			if access.ID == "" {
+				return fmt.Errorf("%w: access %d: %s access needs an ID", ErrInvalidPublicSettings, i, access.Type)
+			}
+			if len(access.Macros) > 0 {
+				return fmt.Errorf("%w: access %d: macros are only valid for macro access", ErrInvalidPublicSettings, i)
+			}
+		default:
+			return fmt.Errorf("%w: access %d: unknown type %q", ErrInvalidPublicSettings, i, access.Type)
+		}
+
+		if len(access.Rights) == 0 {
+			return fmt.Errorf("%w: access %d: no rights", ErrInvalidPublicSettings, i)
+		}
+		for _, right := range access.Rights {
+			switch right {
+			case AccessRightRead, AccessRightWrite, AccessRightReadWithoutDownload:
+			case AccessRightReadWithPassword, AccessRightReadWithPasswordWithoutDownload:
+				// The API never returns the password, so an empty one may
+				// still be set on the link; only removing it is an error.
+				if s.RemovePassword {
+					return fmt.Errorf("%w: access %d: %s needs a password but RemovePassword is set", ErrInvalidPublicSettings, i, right)
+				}
+			default:
+				return fmt.Errorf("%w: access %d: unknown right %q", ErrInvalidPublicSettings, i, right)
+			}
+		}
+	}
+	return nil
+}
+
+func (s PublicSettings) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]interface{}, len(s.Raw)+len(publicSettingsFields))
+	for k, v := range s.Raw {
+		fields[k] = v
+	}
+	for _, k := range publicSettingsFields {
+		delete(fields, k)
+	}
+
+	// A nil value is sent as null, which removes the setting.
+	if s.RemoveExpiry {
+		fields["available_until"] = nil
+	} else if !s.AvailableUntil.IsZero() {
+		fields["available_until"] = s.AvailableUntil.Unix()
+	}
+	if s.ReadOnly != nil {
+		fields["read_only"] = *s.ReadOnly
+	}
+	if s.RemovePassword {
+		fields["password"] = nil
+	} else if s.Password != "" {
+		fields["password"] = s.Password
+	}
+	if s.ExternalOrganizationID != "" {
+		fields["external_organization_id"] = s.ExternalOrganizationID
+	}
+	if s.Accesses != nil {
+		fields["accesses"] = s.Accesses
+	}
+	return json.Marshal(fields)
+}
+
+func (s *PublicSettings) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	*s = PublicSettings{}
+	if v, ok := fields["available_until"]; ok {
+		t, err := parseUnixOrRFC3339(v)
+		if err != nil {
+			return fmt.Errorf("available_until: %w", err)
+		}
+		s.AvailableUntil = t
+	}
+	known := map[string]interface{}{
+		"read_only":                &s.ReadOnly,
+		"password":                 &s.Password,
+		"external_organization_id": &s.ExternalOrganizationID,
+		"accesses":                 &s.Accesses,
+	}
+	for k, dst := range known {
+		if v, ok := fields[k]; ok && !isJSONNull(v) {
+			if err := json.Unmarshal(v, dst); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+		}
+	}
+
+	for _, k := range publicSettingsFields {
+		delete(fields, k)
+	}
+	if len(fields) > 0 {
+		s.Raw = fields
+	}
+	return nil
+}
+
+// parseUnixOrRFC3339 accepts a time as Unix seconds or an RFC 3339 string.
+func parseUnixOrRFC3339(data json.RawMessage) (time.Time, error) {
+	if isJSONNull(data) {
+		return time.Time{}, nil
+	}
+	var seconds int64
+	if err := json.Unmarshal(data, &seconds); err == nil {
+		if seconds == 0 {
+			return time.Time{}, nil
+		}
+		return time.Unix(seconds, 0), nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, text)
+}
+
+func isJSONNull(data json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+}
+
+// AvailablePublicSettings describes which public settings the account may
+// use.
+type AvailablePublicSettings struct {
+	// Accesses lists the subjects access can be granted to, each with the
+	// rights allowed for it.
+	Accesses []Access `json:"accesses"`
+	// Password and AvailableUntil report whether a password and an expiry
+	// can be set.
+	Password       bool `json:"password"`
+	AvailableUntil bool `json:"available_until"`
+
+	// Raw holds the fields of the API response that have no typed
+	// counterpart.
+	Raw map[string]json.RawMessage `json:"-"`
+}
+
+func (a *AvailablePublicSettings) UnmarshalJSON(data []byte) error {
+	type plain AvailablePublicSettings
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, k := range []string{"accesses", "password", "available_until"} {
+		delete(fields, k)
+	}
+	if len(fields) > 0 {
+		p.Raw = fields
+	}
+	*a = AvailablePublicSettings(p)
+	return nil
+}
@@ -0,0 +1,157 @@
+package yandexdisk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicSettingsJSON(t *testing.T) {
+	var settings PublicSettings
+	err := json.Unmarshal([]byte(`{
+		"available_until": 1893456000,
+		"read_only": true,
+		"accesses": [{"type": "macro", "macros": ["employees"], "org_id": "42", "rights": ["read"]}],
+		"password_verbose": {"set": true}
+	}`), &settings)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1893456000, 0), settings.AvailableUntil)
+	if assert.NotNil(t, settings.ReadOnly) {
+		assert.True(t, *settings.ReadOnly)
+	}
+	assert.Equal(t, []Access{{Type: AccessTypeMacro, Macros: []AccessMacro{AccessMacroEmployees}, OrgID: "42", Rights: []AccessRight{AccessRightRead}}}, settings.Accesses)
+	assert.JSONEq(t, `{"set": true}`, string(settings.Raw["password_verbose"]))
+
+	data, err := json.Marshal(settings)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"available_until": 1893456000,
+		"read_only": true,
+		"accesses": [{"type": "macro", "macros": ["employees"], "org_id": "42", "rights": ["read"]}],
+		"password_verbose": {"set": true}
+	}`, string(data))
+
+	data, err = json.Marshal(&PublicSettings{RemoveExpiry: true, RemovePassword: true})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"available_until": null, "password": null}`, string(data))
+
+	readOnly := false
+	data, err = json.Marshal(&PublicSettings{ReadOnly: &readOnly})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"read_only": false}`, string(data))
+
+	err = json.Unmarshal([]byte(`{"available_until": "2030-01-01T00:00:00Z"}`), &settings)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1893456000), settings.AvailableUntil.Unix())
+	assert.Nil(t, settings.Raw)
+}
+
+func TestPublicSettingsValidate(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	valid := []PublicSettings{
+		{},
+		{AvailableUntil: future, Accesses: []Access{{Type: AccessTypeMacro, Macros: []AccessMacro{AccessMacroAll}, Rights: []AccessRight{AccessRightRead}}}},
+		{Password: "secret", Accesses: []Access{{Type: AccessTypeUser, ID: "7", Rights: []AccessRight{AccessRightReadWithPassword}}}},
+		// The password is never returned, so it may already be set.
+		{Accesses: []Access{{Type: AccessTypeUser, ID: "7", Rights: []AccessRight{AccessRightReadWithPassword}}}},
+	}
+	for _, settings := range valid {
+		assert.NoError(t, settings.Validate())
+	}
+
+	invalid := []PublicSettings{
+		{AvailableUntil: time.Now().Add(-time.Hour)},
+		{Accesses: []Access{{Type: AccessTypeMacro, Rights: []AccessRight{AccessRightRead}}}},
+		{Accesses: []Access{{Type: AccessTypeMacro, Macros: []AccessMacro{"everyone"}, Rights: []AccessRight{AccessRightRead}}}},
+		{Accesses: []Access{{Type: AccessTypeGroup, Rights: []AccessRight{AccessRightRead}}}},
+		{Accesses: []Access{{Type: "robot", ID: "1", Rights: []AccessRight{AccessRightRead}}}},
+		{Accesses: []Access{{Type: AccessTypeUser, ID: "7"}}},
+		{Accesses: []Access{{Type: AccessTypeUser, ID: "7", Rights: []AccessRight{"admin"}}}},
+		{RemovePassword: true, Accesses: []Access{{Type: AccessTypeUser, ID: "7", Rights: []AccessRight{AccessRightReadWithPassword}}}},
+		{AvailableUntil: future, RemoveExpiry: true},
+		{Password: "secret", RemovePassword: true},
+	}
+	for _, settings := range invalid {
+		assert.ErrorIs(t, settings.Validate(), ErrInvalidPublicSettings)
+	}
+}
+
+func TestChangePublicSettings(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`{"read_only": false, "accesses": [{"type": "macro", "macros": ["all"], "rights": ["read"]}], "unknown": 1}`))
+		case "PUT":
+			assert.Equal(t, "/resources/public", r.URL.Path)
+			body, _ = io.ReadAll(r.Body)
+			w.Write([]byte(`{"read_only": true, "accesses": [{"type": "macro", "macros": ["all"], "rights": ["read_with_password"]}]}`))
+		}
+	}))
+	defer server.Close()
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	settings, err := client.GetPublicSettings("/disk/shared", false)
+	assert.NoError(t, err)
+	readOnly := true
+	settings.ReadOnly = &readOnly
+	settings.Password = "secret"
+	settings.Accesses[0].Rights = []AccessRight{AccessRightReadWithPassword}
+
+	result, err := client.ChangePublicSettings("/disk/shared", settings)
+	assert.NoError(t, err)
+	assert.True(t, *result.ReadOnly)
+	assert.Equal(t, []AccessRight{AccessRightReadWithPassword}, result.Accesses[0].Rights)
+	assert.JSONEq(t, `{
+		"read_only": true,
+		"password": "secret",
+		"accesses": [{"type": "macro", "macros": ["all"], "rights": ["read_with_password"]}],
+		"unknown": 1
+	}`, string(body))
+
+	body = nil
+	_, err = client.ChangePublicSettings("/disk/shared", &PublicSettings{AvailableUntil: time.Now().Add(-time.Minute)})
+	assert.ErrorIs(t, err, ErrInvalidPublicSettings)
+	assert.Nil(t, body)
+}
+
+func TestChangePublicSettingsKeepsUnsetFields(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`{"read_only": true, "accesses": [{"type": "macro", "macros": ["all"], "rights": ["read_with_password"]}]}`))
+		case "PUT":
+			body, _ = io.ReadAll(r.Body)
+			w.Write([]byte(`{"read_only": true, "accesses": [{"type": "macro", "macros": ["all"], "rights": ["read_with_password"]}]}`))
+		}
+	}))
+	defer server.Close()
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	// A link protected by a password comes back without it and can still
+	// be changed.
+	settings, err := client.GetPublicSettings("/disk/shared", false)
+	assert.NoError(t, err)
+	until := time.Now().Add(time.Hour).Truncate(time.Second)
+	settings.AvailableUntil = until
+
+	_, err = client.ChangePublicSettings("/disk/shared", settings)
+	assert.NoError(t, err)
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"available_until": %d,
+		"read_only": true,
+		"accesses": [{"type": "macro", "macros": ["all"], "rights": ["read_with_password"]}]
+	}`, until.Unix()), string(body))
+
+	// A partial update leaves read_only alone.
+	_, err = client.ChangePublicSettings("/disk/shared", &PublicSettings{AvailableUntil: until})
+	assert.NoError(t, err)
+	assert.JSONEq(t, fmt.Sprintf(`{"available_until": %d}`, until.Unix()), string(body))
+}
@@ -0,0 +1,141 @@
+package yandexdisk
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limits restricts how fast the client talks to the API. Zero values mean
+// "unlimited". API calls and data transfers are limited independently, see
+// WithAPILimits and WithTransferLimits.
+type Limits struct {
+	// RequestsPerSecond is the sustained request rate of the token bucket.
+	RequestsPerSecond float64
+	// Burst is the bucket size; it defaults to 1 when a rate is set.
+	Burst int
+	// MaxConcurrent caps the number of calls or transfers in flight.
+	MaxConcurrent int
+}
+
+// WithAPILimits limits metadata and management calls such as GetMeta or Copy.
+// When the API answers 429 the rate is temporarily reduced and then slowly
+// raised back to the configured value.
+func WithAPILimits(limits Limits) Option {
+	return func(c *Client) {
+		c.apiLimiter = newLimiter(limits)
+	}
+}
+
+// WithTransferLimits limits uploads and downloads of file content.
+func WithTransferLimits(limits Limits) Option {
+	return func(c *Client) {
+		c.transferLimiter = newLimiter(limits)
+	}
+}
+
+// limiter is a token bucket combined with a concurrency semaphore. A nil
+// *limiter imposes no limits, so callers never need to check for it.
+type limiter struct {
+	mu      sync.Mutex
+	rate    float64
+	maxRate float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+	sem     chan struct{}
+}
+
+func newLimiter(limits Limits) *limiter {
+	if limits.RequestsPerSecond <= 0 && limits.MaxConcurrent <= 0 {
+		return nil
+	}
+
+	l := &limiter{
+		rate:    limits.RequestsPerSecond,
+		maxRate: limits.RequestsPerSecond,
+		burst:   math.Max(float64(limits.Burst), 1),
+	}
+	l.tokens = l.burst
+	if limits.MaxConcurrent > 0 {
+		l.sem = make(chan struct{}, limits.MaxConcurrent)
+	}
+	return l
+}
+
+// acquire takes a concurrency slot. The returned function releases it.
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil || l.sem == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-l.sem })
+	}, nil
+}
+
+// wait blocks until the token bucket allows another request.
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil || l.maxRate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.refill(now)
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if err := sleepContext(ctx, delay); err != nil {
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (l *limiter) refill(now time.Time) {
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+}
+
+// throttle halves the current rate after the API answered 429.
+func (l *limiter) throttle() {
+	if l == nil || l.maxRate <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	l.rate = math.Max(l.rate/2, l.maxRate/32)
+}
+
+// recover raises a throttled rate back towards the configured one.
+func (l *limiter) recover() {
+	if l == nil || l.maxRate <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate < l.maxRate {
+		l.refill(time.Now())
+		l.rate = math.Min(l.rate*1.1, l.maxRate)
+	}
+}
@@ -0,0 +1,111 @@
+package yandexdisk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLimiterUnlimited(t *testing.T) {
+	l := newLimiter(Limits{})
+	assert.Nil(t, l)
+
+	release, err := l.acquire(context.Background())
+	assert.NoError(t, err)
+	release()
+	assert.NoError(t, l.wait(context.Background()))
+}
+
+func TestLimiterRate(t *testing.T) {
+	l := newLimiter(Limits{RequestsPerSecond: 50, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, l.wait(context.Background()))
+	}
+
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestLimiterWaitCanceled(t *testing.T) {
+	l := newLimiter(Limits{RequestsPerSecond: 0.1, Burst: 1})
+	assert.NoError(t, l.wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.wait(ctx), context.DeadlineExceeded)
+}
+
+func TestLimiterThrottleAndRecover(t *testing.T) {
+	l := newLimiter(Limits{RequestsPerSecond: 10})
+
+	l.throttle()
+	l.throttle()
+	assert.Equal(t, 2.5, l.rate)
+
+	for i := 0; i < 100; i++ {
+		l.recover()
+	}
+	assert.Equal(t, 10.0, l.rate)
+}
+
+func TestAPILimitsMaxConcurrent(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", WithBaseURL(server.URL), WithAPILimits(Limits{MaxConcurrent: 2}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetMeta("/disk/file.txt", nil)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+}
+
+func TestAPILimitsThrottleOnTooManyRequests(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token",
+		WithBaseURL(server.URL),
+		WithRetryPolicy(testRetryPolicy),
+		WithAPILimits(Limits{RequestsPerSecond: 1000, Burst: 10}),
+	)
+
+	_, err := client.GetCapacity()
+	assert.NoError(t, err)
+	assert.Less(t, client.apiLimiter.rate, 1000.0)
+}
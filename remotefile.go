@@ -0,0 +1,236 @@
+package yandexdisk
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+)
+
+const (
+	remoteBlockSize   = 64 << 10
+	remoteCacheBlocks = 16
+)
+
+// RemoteFile gives random access to a file on Disk without downloading it.
+// Reads are served from a small cache of 64 KiB blocks that are fetched with
+// DownloadRange. ReadAt is safe for concurrent use; Read and Seek share an
+// offset and are not.
+type RemoteFile struct {
+	disk     Disk
+	ctx      context.Context
+	path     string
+	resource *Resource
+	offset   int64
+
+	mu     sync.Mutex
+	blocks map[int64]*list.Element
+	lru    *list.List
+	closed bool
+}
+
+var (
+	_ io.ReaderAt   = (*RemoteFile)(nil)
+	_ io.ReadSeeker = (*RemoteFile)(nil)
+	_ io.Closer     = (*RemoteFile)(nil)
+)
+
+type cachedBlock struct {
+	index int64
+	data  []byte
+}
+
+// Open opens the file at remotePath for random access. All requests are made
+// with ctx.
+func (c *Client) Open(ctx context.Context, remotePath string) (*RemoteFile, error) {
+	return OpenRemoteFile(ctx, c, remotePath)
+}
+
+// OpenRemoteFile opens the file at remotePath on d like Client.Open.
+func OpenRemoteFile(ctx context.Context, d Disk, remotePath string) (*RemoteFile, error) {
+	resource, err := d.GetMetaContext(ctx, remotePath, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resource.IsDir() {
+		return nil, fmt.Errorf("cannot open %s: is a directory", remotePath)
+	}
+
+	return &RemoteFile{
+		disk:     d,
+		ctx:      ctx,
+		path:     remotePath,
+		resource: resource,
+		blocks:   map[int64]*list.Element{},
+		lru:      list.New(),
+	}, nil
+}
+
+// Resource returns the metadata of the file at the time it was opened.
+func (f *RemoteFile) Resource() *Resource {
+	return f.resource
+}
+
+func (f *RemoteFile) Size() int64 {
+	return f.resource.Size
+}
+
+// ReadAt reads len(p) bytes starting at off. Consecutive blocks missing from
+// the cache are fetched with a single request.
+func (f *RemoteFile) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("read %s: negative offset %d", f.path, off)
+	}
+	size := f.resource.Size
+	if off >= size {
+		return 0, io.EOF
+	}
+
+	end := min(off+int64(len(p)), size)
+	first, last := off/remoteBlockSize, (end-1)/remoteBlockSize
+
+	n := 0
+	for index := first; index <= last; {
+		data, ok, err := f.cached(index)
+		if err != nil {
+			return n, err
+		}
+		if !ok {
+			// Fetch the run of missing blocks starting at index.
+			run := index + 1
+			for run <= last && !f.has(run) {
+				run++
+			}
+			if data, err = f.fetch(index, run); err != nil {
+				return n, err
+			}
+		}
+
+		// data holds one or more blocks starting at index.
+		dataStart := index * remoteBlockSize
+		from := max(off-dataStart, 0)
+		to := min(end-dataStart, int64(len(data)))
+		n += copy(p[n:], data[from:to])
+		index += (int64(len(data)) + remoteBlockSize - 1) / remoteBlockSize
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Read reads from the current offset and advances it.
+func (f *RemoteFile) Read(p []byte) (int, error) {
+	n, err := f.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (f *RemoteFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.resource.Size
+	default:
+		return 0, fmt.Errorf("seek %s: invalid whence %d", f.path, whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("seek %s: negative offset %d", f.path, offset)
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// Close releases the block cache. Reads after Close fail with fs.ErrClosed.
+func (f *RemoteFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	f.blocks = map[int64]*list.Element{}
+	f.lru.Init()
+	return nil
+}
+
+func (f *RemoteFile) cached(index int64) ([]byte, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil, false, fmt.Errorf("read %s: %w", f.path, fs.ErrClosed)
+	}
+	elem, ok := f.blocks[index]
+	if !ok {
+		return nil, false, nil
+	}
+	f.lru.MoveToFront(elem)
+	return elem.Value.(*cachedBlock).data, true, nil
+}
+
+func (f *RemoteFile) has(index int64) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.blocks[index]
+	return ok
+}
+
+func (f *RemoteFile) store(index int64, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return
+	}
+	if elem, ok := f.blocks[index]; ok {
+		f.lru.MoveToFront(elem)
+		return
+	}
+	f.blocks[index] = f.lru.PushFront(&cachedBlock{index: index, data: data})
+	for f.lru.Len() > remoteCacheBlocks {
+		oldest := f.lru.Back()
+		f.lru.Remove(oldest)
+		delete(f.blocks, oldest.Value.(*cachedBlock).index)
+	}
+}
+
+// fetch downloads the blocks in [first, last), adds them to the cache and
+// returns their content.
+func (f *RemoteFile) fetch(first, last int64) ([]byte, error) {
+	start := first * remoteBlockSize
+	length := min(last*remoteBlockSize, f.resource.Size) - start
+
+	data, err := f.readRange(start, length)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", f.path, err)
+	}
+
+	for index := first; index < last; index++ {
+		from := (index - first) * remoteBlockSize
+		to := min(from+remoteBlockSize, int64(len(data)))
+		f.store(index, data[from:to:to])
+	}
+	return data, nil
+}
+
+// readRange reads length bytes at start.
+func (f *RemoteFile) readRange(start, length int64) ([]byte, error) {
+	body, err := f.disk.DownloadRange(f.ctx, f.path, start, length)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(body, data); err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	return data, nil
+}
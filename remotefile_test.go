@@ -0,0 +1,69 @@
+package yandexdisk
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteFileReadAt(t *testing.T) {
+	content := strings.Repeat("0123456789", 20000) // 200000 bytes, 4 blocks
+	server := newFileServer(t, content)
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	f, err := client.Open(context.Background(), "/disk/file.bin")
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, int64(200000), f.Size())
+
+	// The tail of the file, like a zip central directory.
+	tail := make([]byte, 22)
+	n, err := f.ReadAt(tail, f.Size()-22)
+	assert.NoError(t, err)
+	assert.Equal(t, 22, n)
+	assert.Equal(t, content[len(content)-22:], string(tail))
+
+	// A second read from the same block is served from the cache.
+	n, err = f.ReadAt(tail, f.Size()-40)
+	assert.NoError(t, err)
+	assert.Equal(t, content[len(content)-40:len(content)-18], string(tail[:n]))
+	assert.Equal(t, []string{"bytes=196608-199999"}, server.ranges)
+
+	// Reading past the end returns what is left and io.EOF.
+	buf := make([]byte, 100)
+	n, err = f.ReadAt(buf, f.Size()-10)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 10, n)
+}
+
+func TestRemoteFileFetchesMissingBlocksTogether(t *testing.T) {
+	content := strings.Repeat("0123456789", 20000)
+	server := newFileServer(t, content)
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	f, err := client.Open(context.Background(), "/disk/file.bin")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	buf := make([]byte, 150000)
+	n, err := f.ReadAt(buf, 20000)
+	assert.NoError(t, err)
+	assert.Equal(t, 150000, n)
+	assert.Equal(t, content[20000:170000], string(buf))
+	assert.Equal(t, []string{"bytes=0-196607"}, server.ranges)
+
+	_, err = f.Seek(100000, io.SeekStart)
+	assert.NoError(t, err)
+	data, err := io.ReadAll(f)
+	assert.NoError(t, err)
+	assert.Equal(t, content[100000:], string(data))
+	assert.Equal(t, []string{"bytes=0-196607", "bytes=196608-199999"}, server.ranges)
+
+	f.Close()
+	_, err = f.ReadAt(make([]byte, 1), 0)
+	assert.ErrorIs(t, err, fs.ErrClosed)
+}
@@ -0,0 +1,187 @@
+package yandexdisk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+const (
+	DefaultMaxResumes = 5
+	partialSuffix     = ".part"
+	// validatorSuffix is appended to the partial file name for the file
+	// that records which version of the remote file it holds.
+	validatorSuffix = ".validator"
+)
+
+// WithMaxResumes sets how many times DownloadFile continues an interrupted
+// transfer before giving up. Zero disables resuming.
+func WithMaxResumes(n int) Option {
+	return func(c *Client) {
+		c.maxResumes = n
+	}
+}
+
+// downloadFile downloads into localPath + ".part", continuing from whatever
+// that file already holds, and renames it to localPath once it is complete.
+// The ETag or Last-Modified date of the remote file is kept next to the
+// partial file and sent as If-Range, so a partial file of an older version
+// is downloaded again from the start; one without a recorded validator is
+// never continued. When the transfer breaks it is resumed with a Range
+// request; when the href
+// returned by getLink has expired a fresh one is requested. With checksum
+// verification enabled the completed file is checked against getMeta.
+func (c *Client) downloadFile(ctx context.Context, localPath string, auth bool, getLink func(ctx context.Context) (*Link, error), getMeta func(ctx context.Context) (*Resource, error)) error {
+	var resource *Resource
+	if c.verifyChecksums {
+		var err error
+		if resource, err = getMeta(ctx); err != nil {
+			return err
+		}
+	}
+
+	link, err := getLink(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	partPath := localPath + partialSuffix
+	file, err := os.OpenFile(partPath, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+	offset := info.Size()
+
+	validatorPath := partPath + validatorSuffix
+	var validator string
+	if offset > 0 {
+		if data, err := os.ReadFile(validatorPath); err == nil {
+			validator = string(data)
+		}
+		if validator == "" {
+			// Nothing tells which version the partial file holds.
+			if err := truncate(file, nil); err != nil {
+				return err
+			}
+			offset = 0
+		}
+	}
+
+	for resumes := 0; ; resumes++ {
+		complete, n, v, err := c.downloadRange(ctx, file, link.Href, auth, offset, validator)
+		offset = n
+		if v != validator {
+			validator = v
+			if err := os.WriteFile(validatorPath, []byte(v), 0644); err != nil {
+				return fmt.Errorf("failed to write file: %w", err)
+			}
+		}
+		if complete {
+			break
+		}
+
+		if resumes >= c.maxResumes || ctx.Err() != nil {
+			return err
+		}
+
+		var statusErr *TransferError
+		if errors.As(err, &statusErr) && isExpiredLinkStatus(statusErr.StatusCode) {
+			if link, err = getLink(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := sleepContext(ctx, c.retryPolicy.backoff(resumes+1)); err != nil {
+			return err
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if resource != nil {
+		if err := verifyFile(partPath, resource); err != nil {
+			os.Remove(partPath)
+			os.Remove(validatorPath)
+			return err
+		}
+	}
+	if err := os.Rename(partPath, localPath); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	os.Remove(validatorPath)
+	return nil
+}
+
+// downloadRange appends the content of href from offset on to file, provided
+// the remote file still matches validator. It returns whether the file is
+// complete, the number of bytes it now holds and the validator of the
+// version it holds.
+func (c *Client) downloadRange(ctx context.Context, file *os.File, href string, auth bool, offset int64, validator string) (bool, int64, string, error) {
+	body, err := c.openRangeIf(ctx, href, auth, offset, -1, validator)
+	if err != nil {
+		var statusErr *TransferError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			// The partial file is already complete, or it is larger than the
+			// remote file and has to be fetched again.
+			if total, ok := contentRangeTotal(statusErr.ContentRange); ok && total == offset {
+				return true, offset, validator, nil
+			}
+			return false, 0, "", truncate(file, err)
+		}
+		return false, offset, validator, err
+	}
+	defer body.Close()
+
+	if offset > 0 && (!body.Partial || body.Validator != validator) {
+		// The server sent the whole file, or a range of another version of
+		// it, so start over.
+		if body.Partial {
+			return false, 0, "", truncate(file, errors.New("download failed: remote file changed"))
+		}
+		offset = 0
+		if err := truncate(file, nil); err != nil {
+			return false, 0, "", err
+		}
+	}
+	validator = body.Validator
+
+	n, err := io.Copy(io.NewOffsetWriter(file, offset), body)
+	offset += n
+	if err != nil {
+		return false, offset, validator, fmt.Errorf("download failed: %w", err)
+	}
+
+	if body.Size >= 0 && offset < body.Size {
+		return false, offset, validator, fmt.Errorf("download failed: %w", io.ErrUnexpectedEOF)
+	}
+	return true, offset, validator, nil
+}
+
+func truncate(file *os.File, cause error) error {
+	if err := file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return cause
+}
+
+// isExpiredLinkStatus reports statuses returned for download hrefs that are
+// no longer valid.
+func isExpiredLinkStatus(status int) bool {
+	return status == http.StatusForbidden || status == http.StatusNotFound || status == http.StatusGone
+}
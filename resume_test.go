@@ -0,0 +1,156 @@
+package yandexdisk
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writePartial leaves a partial file of the server's current content behind,
+// as an interrupted DownloadFile would.
+func (s *fileServer) writePartial(t *testing.T, localPath, data string) {
+	assert.NoError(t, os.WriteFile(localPath+partialSuffix, []byte(data), 0644))
+	assert.NoError(t, os.WriteFile(localPath+partialSuffix+validatorSuffix, []byte(s.etag()), 0644))
+}
+
+func newResumeClient(server *fileServer, opts ...Option) *Client {
+	opts = append([]Option{WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy)}, opts...)
+	return NewClient("test-token", opts...)
+}
+
+func TestDownloadFileResumesAfterInterruption(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	server := newFileServer(t, content)
+	server.interrupts = 1
+
+	localPath := filepath.Join(t.TempDir(), "file.txt")
+	err := newResumeClient(server).DownloadFile("/disk/file.txt", localPath)
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(localPath)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(data))
+	assert.NoFileExists(t, localPath+partialSuffix)
+	assert.Equal(t, []string{"", "bytes=500-"}, server.ranges)
+}
+
+func TestDownloadFileContinuesPartialFile(t *testing.T) {
+	server := newFileServer(t, "0123456789")
+
+	localPath := filepath.Join(t.TempDir(), "file.txt")
+	server.writePartial(t, localPath, "01234")
+
+	err := newResumeClient(server).DownloadFile("/disk/file.txt", localPath)
+	assert.NoError(t, err)
+
+	data, _ := os.ReadFile(localPath)
+	assert.Equal(t, "0123456789", string(data))
+	assert.Equal(t, []string{"bytes=5-"}, server.ranges)
+}
+
+func TestDownloadFileCompletePartialFile(t *testing.T) {
+	server := newFileServer(t, "0123456789")
+
+	localPath := filepath.Join(t.TempDir(), "file.txt")
+	server.writePartial(t, localPath, "0123456789")
+
+	err := newResumeClient(server).DownloadFile("/disk/file.txt", localPath)
+	assert.NoError(t, err)
+
+	data, _ := os.ReadFile(localPath)
+	assert.Equal(t, "0123456789", string(data))
+	assert.Equal(t, []string{"bytes=10-"}, server.ranges)
+}
+
+func TestDownloadFileRestartsOversizedPartialFile(t *testing.T) {
+	server := newFileServer(t, "0123456789")
+
+	localPath := filepath.Join(t.TempDir(), "file.txt")
+	server.writePartial(t, localPath, "stale content from before")
+
+	err := newResumeClient(server).DownloadFile("/disk/file.txt", localPath)
+	assert.NoError(t, err)
+
+	data, _ := os.ReadFile(localPath)
+	assert.Equal(t, "0123456789", string(data))
+}
+
+func TestDownloadFileRestartsWhenRemoteFileChanged(t *testing.T) {
+	server := newFileServer(t, "OLDCONTENT-OLDCONTENT")
+	server.interrupts = 1
+
+	localPath := filepath.Join(t.TempDir(), "file.txt")
+	err := newResumeClient(server, WithMaxResumes(0)).DownloadFile("/disk/file.txt", localPath)
+	assert.Error(t, err)
+	assert.FileExists(t, localPath+partialSuffix)
+
+	server.content = []byte("NEWCONTENT-NEWCONTENT")
+	err = newResumeClient(server).DownloadFile("/disk/file.txt", localPath)
+	assert.NoError(t, err)
+
+	data, _ := os.ReadFile(localPath)
+	assert.Equal(t, "NEWCONTENT-NEWCONTENT", string(data))
+	assert.NoFileExists(t, localPath+partialSuffix+validatorSuffix)
+}
+
+func TestDownloadFileRestartsPartialFileWithoutValidator(t *testing.T) {
+	server := newFileServer(t, "0123456789")
+
+	localPath := filepath.Join(t.TempDir(), "file.txt")
+	assert.NoError(t, os.WriteFile(localPath+partialSuffix, []byte("OLDCO"), 0644))
+
+	err := newResumeClient(server).DownloadFile("/disk/file.txt", localPath)
+	assert.NoError(t, err)
+
+	data, _ := os.ReadFile(localPath)
+	assert.Equal(t, "0123456789", string(data))
+	assert.Equal(t, []string{""}, server.ranges)
+}
+
+func TestDownloadFileRefreshesExpiredLink(t *testing.T) {
+	server := newFileServer(t, "0123456789")
+	server.expiredURLs = 1
+
+	localPath := filepath.Join(t.TempDir(), "file.txt")
+	err := newResumeClient(server).DownloadFile("/disk/file.txt", localPath)
+	assert.NoError(t, err)
+
+	data, _ := os.ReadFile(localPath)
+	assert.Equal(t, "0123456789", string(data))
+	assert.Equal(t, 2, server.links)
+}
+
+func TestDownloadFileKeepsPartialFileOnFailure(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	server := newFileServer(t, content)
+	server.interrupts = 1
+
+	localPath := filepath.Join(t.TempDir(), "file.txt")
+	err := newResumeClient(server, WithMaxResumes(0)).DownloadFile("/disk/file.txt", localPath)
+	assert.Error(t, err)
+
+	assert.NoFileExists(t, localPath)
+	data, _ := os.ReadFile(localPath + partialSuffix)
+	assert.Equal(t, content[:500], string(data))
+}
+
+func TestParseContentRange(t *testing.T) {
+	start, total, ok := parseContentRange("bytes 100-199/1000")
+	assert.True(t, ok)
+	assert.Equal(t, int64(100), start)
+	assert.Equal(t, int64(1000), total)
+
+	_, total, ok = parseContentRange("bytes 0-9/*")
+	assert.True(t, ok)
+	assert.Equal(t, int64(-1), total)
+
+	_, _, ok = parseContentRange("bytes */1000")
+	assert.False(t, ok)
+
+	total, ok = contentRangeTotal("bytes */1000")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1000), total)
+}
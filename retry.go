@@ -0,0 +1,178 @@
+package yandexdisk
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. Requests that got a
+// 429 response are always safe to replay; network errors and 5xx responses
+// are only retried for idempotent methods unless RetryNonIdempotent is set.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter randomizes each backoff by up to the given fraction (0..1).
+	Jitter float64
+	// RetryNonIdempotent allows replaying POST and PATCH requests after a
+	// network error or 5xx response, which may repeat their side effects.
+	RetryNonIdempotent bool
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy. Pass RetryPolicy{} to disable
+// retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	if delay < 0 {
+		return 0
+	}
+	return time.Duration(delay)
+}
+
+// shouldRetry reports whether a request should be attempted again after the
+// given outcome and how long to wait before doing so. It gives up when the
+// wait would outlast the deadline of ctx, so that the caller gets the last
+// response or error rather than context.DeadlineExceeded.
+func (p RetryPolicy) shouldRetry(ctx context.Context, attempt int, replay replayMode, resp *http.Response, err error) (time.Duration, bool) {
+	delay, retry := p.retryDelay(attempt, replay, resp, err)
+	if !retry || ctx.Err() != nil {
+		return 0, false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+		return 0, false
+	}
+	return delay, true
+}
+
+func (p RetryPolicy) retryDelay(attempt int, replay replayMode, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts || replay == replayNever {
+		return 0, false
+	}
+
+	replayable := replay == replaySafe || p.RetryNonIdempotent
+	if err != nil {
+		return p.backoff(attempt), replayable && !isPermanentNetError(err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case isRetryableStatus(resp.StatusCode):
+		if !replayable {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+			delay = p.MaxBackoff
+		}
+		return delay, true
+	}
+	return p.backoff(attempt), true
+}
+
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// isPermanentNetError reports transport errors that a retry cannot fix.
+func isPermanentNetError(err error) bool {
+	var certErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	return errors.As(err, &certErr) || errors.As(err, &authorityErr) || errors.As(err, &hostnameErr)
+}
+
+// replayMode tells the retry loop whether a request may be sent again.
+type replayMode int
+
+const (
+	// replayNever is used when the request body cannot be produced twice.
+	replayNever replayMode = iota
+	// replayUnsafe marks non-idempotent requests such as POST.
+	replayUnsafe
+	// replaySafe marks idempotent requests.
+	replaySafe
+)
+
+func replayModeFor(method string) replayMode {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return replaySafe
+	}
+	return replayUnsafe
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func drainBody(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
@@ -0,0 +1,184 @@
+package yandexdisk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource supplies the access token for every request. It mirrors
+// oauth2.TokenSource from golang.org/x/oauth2 but returns a *Token, so it is
+// not interchangeable with it; use FromOAuth2 to plug in an oauth2 source.
+//
+// Token must return a token or an error; a nil token with a nil error fails
+// the request.
+//
+// A TokenSource may also implement Invalidate(*Token). When the API rejects
+// a token with 401 Unauthorized, the client calls it with the rejected token
+// and retries the request once if Token then returns a different one.
+type TokenSource interface {
+	Token() (*Token, error)
+}
+
+// TokenSourceFunc adapts a function, for example one that reads the token
+// from a secrets store, to a TokenSource.
+type TokenSourceFunc func() (*Token, error)
+
+func (f TokenSourceFunc) Token() (*Token, error) {
+	return f()
+}
+
+// FromOAuth2 returns a TokenSource that takes the access token and its
+// expiry from token, which is meant to wrap the Token method of an
+// oauth2.TokenSource without this package depending on golang.org/x/oauth2:
+//
+//	src := yandexdisk.FromOAuth2(func() (string, time.Time, error) {
+//		t, err := oauth2Source.Token()
+//		if err != nil {
+//			return "", time.Time{}, err
+//		}
+//		return t.AccessToken, t.Expiry, nil
+//	})
+//
+// token is called for every request, so it should cache the token itself,
+// as oauth2.ReuseTokenSource does. An empty access token fails the request.
+func FromOAuth2(token func() (accessToken string, expiry time.Time, err error)) TokenSource {
+	return TokenSourceFunc(func() (*Token, error) {
+		accessToken, expiry, err := token()
+		if err != nil {
+			return nil, err
+		}
+		if accessToken == "" {
+			return nil, errNoToken
+		}
+		return &Token{AccessToken: accessToken, Expiry: expiry}, nil
+	})
+}
+
+type staticTokenSource struct {
+	token *Token
+}
+
+// StaticTokenSource returns a TokenSource that always returns token.
+func StaticTokenSource(token *Token) TokenSource {
+	return staticTokenSource{token: token}
+}
+
+func (s staticTokenSource) Token() (*Token, error) {
+	return s.token, nil
+}
+
+// ReuseTokenSource returns a TokenSource that returns token while it is
+// valid and asks src for a new one once it expires or is invalidated. It is
+// safe for concurrent use.
+func ReuseTokenSource(token *Token, src TokenSource) TokenSource {
+	return &reuseTokenSource{token: token, src: src}
+}
+
+type reuseTokenSource struct {
+	mu    sync.Mutex
+	token *Token
+	src   TokenSource
+}
+
+func (s *reuseTokenSource) Token() (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.Valid() {
+		return s.token, nil
+	}
+	token, err := s.src.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, errNoToken
+	}
+	s.token = token
+	return token, nil
+}
+
+// Invalidate drops the cached token if it is the rejected one.
+func (s *reuseTokenSource) Invalidate(rejected *Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && s.token.AccessToken == rejected.AccessToken {
+		s.token = nil
+	}
+}
+
+// TokenSource returns a TokenSource that starts with token and refreshes it
+// with its refresh token when it expires. All refresh requests use ctx.
+func (c *OAuthConfig) TokenSource(ctx context.Context, token *Token) TokenSource {
+	refresher := &tokenRefresher{ctx: ctx, config: c}
+	if token != nil {
+		refresher.refreshToken = token.RefreshToken
+	}
+	return ReuseTokenSource(token, refresher)
+}
+
+type tokenRefresher struct {
+	ctx          context.Context
+	config       *OAuthConfig
+	refreshToken string
+}
+
+// Token is only called by reuseTokenSource with its lock held.
+func (r *tokenRefresher) Token() (*Token, error) {
+	if r.refreshToken == "" {
+		return nil, errors.New("oauth: token expired and refresh token is not set")
+	}
+	token, err := r.config.Refresh(r.ctx, r.refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	r.refreshToken = token.RefreshToken
+	return token, nil
+}
+
+// errNoToken is returned when a TokenSource returns neither a token nor an
+// error.
+var errNoToken = errors.New("token source returned no token")
+
+// WithTokenSource makes the client take its access token from src instead of
+// the token passed to NewClient.
+func WithTokenSource(src TokenSource) Option {
+	return func(c *Client) {
+		c.tokenSource = src
+	}
+}
+
+// authorize sets the Authorization header of req to the current token.
+func (c *Client) authorize(req *http.Request) error {
+	token, err := c.tokenSource.Token()
+	if err == nil && token == nil {
+		err = errNoToken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+	req.Header.Set("Authorization", "OAuth "+token.AccessToken)
+	return nil
+}
+
+// reauthorize is called after req was rejected with 401 Unauthorized. It
+// invalidates the token req was sent with and reports whether the token
+// source now returns a different one.
+func (c *Client) reauthorize(req *http.Request) bool {
+	rejected := strings.TrimPrefix(req.Header.Get("Authorization"), "OAuth ")
+	if rejected == "" {
+		return false
+	}
+	if inv, ok := c.tokenSource.(interface{ Invalidate(*Token) }); ok {
+		inv.Invalidate(&Token{AccessToken: rejected})
+	}
+
+	token, err := c.tokenSource.Token()
+	return err == nil && token != nil && token.AccessToken != rejected
+}
@@ -0,0 +1,138 @@
+package yandexdisk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAuthServer accepts only requests authorized with the token returned by
+// valid, and counts all requests.
+func newAuthServer(t *testing.T, valid func() string) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Header.Get("Authorization") != "OAuth "+valid() {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message": "Unauthorized", "error": "UnauthorizedError"}`))
+			return
+		}
+		w.Write([]byte(`{"name": "file.txt", "type": "file"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestTokenSourceRetriesWithRotatedToken(t *testing.T) {
+	var mu sync.Mutex
+	stored := "old-token"
+	server, calls := newAuthServer(t, func() string { return "new-token" })
+
+	source := TokenSourceFunc(func() (*Token, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		token := &Token{AccessToken: stored}
+		stored = "new-token" // rotated in the secrets store after the first read
+		return token, nil
+	})
+	client := NewClient("", WithBaseURL(server.URL), WithTokenSource(source))
+
+	_, err := client.GetMeta("/disk/file.txt", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
+
+func TestTokenSourceDoesNotRetrySameToken(t *testing.T) {
+	server, calls := newAuthServer(t, func() string { return "other-token" })
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	_, err := client.GetMeta("/disk/file.txt", nil)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestOAuthTokenSourceRefreshes(t *testing.T) {
+	oauthServer, _ := newOAuthServer(t)
+	config := newTestOAuthConfig(oauthServer)
+	server, calls := newAuthServer(t, func() string { return "access-2" })
+
+	expired := &Token{AccessToken: "access-1", RefreshToken: "refresh-1", Expiry: time.Now().Add(-time.Minute)}
+	source := config.TokenSource(context.Background(), expired)
+	client := NewClient("", WithBaseURL(server.URL), WithTokenSource(source))
+
+	_, err := client.GetMeta("/disk/file.txt", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	token, err := source.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "access-2", token.AccessToken)
+	assert.Equal(t, "refresh-1", token.RefreshToken)
+}
+
+func TestReuseTokenSourceInvalidate(t *testing.T) {
+	var fetched int32
+	source := ReuseTokenSource(nil, TokenSourceFunc(func() (*Token, error) {
+		atomic.AddInt32(&fetched, 1)
+		return &Token{AccessToken: "fresh"}, nil
+	}))
+
+	token, _ := source.Token()
+	assert.Equal(t, "fresh", token.AccessToken)
+	source.Token()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetched))
+
+	source.(interface{ Invalidate(*Token) }).Invalidate(&Token{AccessToken: "fresh"})
+	source.Token()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetched))
+}
+
+func TestTokenSourceWithoutToken(t *testing.T) {
+	server, calls := newAuthServer(t, func() string { return "test-token" })
+	none := TokenSourceFunc(func() (*Token, error) { return nil, nil })
+
+	for _, src := range []TokenSource{none, ReuseTokenSource(nil, none)} {
+		client := NewClient("", WithBaseURL(server.URL), WithTokenSource(src))
+		_, err := client.GetMeta("/disk/file.txt", nil)
+		assert.ErrorIs(t, err, errNoToken)
+	}
+	assert.Equal(t, int32(0), atomic.LoadInt32(calls))
+}
+
+func TestFromOAuth2(t *testing.T) {
+	expiry := time.Now().Add(time.Hour)
+	token, err := FromOAuth2(func() (string, time.Time, error) { return "token", expiry, nil }).Token()
+	assert.NoError(t, err)
+	assert.Equal(t, &Token{AccessToken: "token", Expiry: expiry}, token)
+
+	failed := errors.New("refresh failed")
+	_, err = FromOAuth2(func() (string, time.Time, error) { return "", time.Time{}, failed }).Token()
+	assert.ErrorIs(t, err, failed)
+	_, err = FromOAuth2(func() (string, time.Time, error) { return "", time.Time{}, nil }).Token()
+	assert.ErrorIs(t, err, errNoToken)
+}
+
+func TestFromOAuth2RetriesWithRotatedToken(t *testing.T) {
+	server, calls := newAuthServer(t, func() string { return "new-token" })
+
+	// The wrapped source hands out a rotated token after the first call.
+	var fetched int32
+	source := FromOAuth2(func() (string, time.Time, error) {
+		if atomic.AddInt32(&fetched, 1) == 1 {
+			return "old-token", time.Time{}, nil
+		}
+		return "new-token", time.Time{}, nil
+	})
+
+	client := NewClient("", WithBaseURL(server.URL), WithTokenSource(source))
+	_, err := client.GetMeta("/disk/file.txt", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
@@ -0,0 +1,622 @@
+package yandexdisk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// linkTTL is how long DownloadRange reuses a download href before it requests
+// a fresh one. Expired hrefs are also detected from the response status.
+const linkTTL = 10 * time.Minute
+
+type UploadOptions struct {
+	Overwrite bool
+	// Size is the number of bytes the reader will produce. When it is not
+	// positive the size is detected from readers that expose it (bytes.Reader,
+	// strings.Reader, *os.File, io.Seeker); otherwise the body is sent with
+	// chunked transfer encoding.
+	Size int64
+}
+
+// Upload streams r to remotePath without buffering it in memory. If r
+// implements io.Seeker the transfer can be retried by rewinding it;
+// otherwise it is attempted only once. With checksum verification enabled the
+// uploaded data is checked against the metadata of remotePath.
+func (c *Client) Upload(ctx context.Context, r io.Reader, remotePath string, opts *UploadOptions) (*UploadResult, error) {
+	if opts == nil {
+		opts = &UploadOptions{}
+	}
+
+	link, err := c.getUploadLink(ctx, remotePath, opts.Overwrite)
+	if err != nil {
+		return nil, err
+	}
+
+	size := opts.Size
+	if size <= 0 {
+		size = readerSize(r)
+	}
+
+	replay := replayNever
+	var start int64
+	seeker, seekable := r.(io.Seeker)
+	if seekable {
+		if start, err = seeker.Seek(0, io.SeekCurrent); err == nil {
+			replay = replaySafe
+		}
+	}
+
+	ctx, cancel := c.withTimeout(ctx, c.transferTimeout)
+	defer cancel()
+
+	release, err := c.transferLimiter.acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("upload failed: %w", err)
+	}
+	defer release()
+
+	progress := c.progressFunc(ctx)
+	var sums *checksums
+	attempt := 0
+	resp, err := c.do(ctx, c.transferLimiter, replay, func(ctx context.Context) (*http.Request, error) {
+		attempt++
+		if attempt > 1 {
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return nil, fmt.Errorf("failed to rewind upload body: %w", err)
+			}
+		}
+
+		src := r
+		if size > 0 {
+			src = io.LimitReader(r, size)
+		}
+		if c.verifyChecksums {
+			sums = newChecksums()
+			src = io.TeeReader(src, sums)
+		}
+
+		var body io.Reader = http.NoBody
+		if size != 0 {
+			body = io.NopCloser(newProgressReader(src, size, 0, progress))
+		}
+
+		req, err := c.newRequest(ctx, "PUT", link.Href, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create upload request: %w", err)
+		}
+		if size > 0 {
+			req.ContentLength = size
+		}
+
+		if err := c.authorize(req); err != nil {
+			return nil, err
+		}
+		return req, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated, http.StatusAccepted:
+	default:
+		return nil, newTransferError("upload", resp)
+	}
+
+	result := &UploadResult{
+		Status:  resp.StatusCode,
+		Success: true,
+	}
+	if c.verifyChecksums {
+		if sums == nil {
+			sums = newChecksums()
+		}
+		resource, err := c.GetMetaContext(ctx, remotePath, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := sums.verify(resource); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (c *Client) getUploadLink(ctx context.Context, remotePath string, overwrite bool) (*Link, error) {
+	queryParams := url.Values{}
+	queryParams.Set("path", remotePath)
+	if overwrite {
+		queryParams.Set("overwrite", "true")
+	} else {
+		queryParams.Set("overwrite", "false")
+	}
+
+	data, err := c.request(ctx, "GET", "/resources/upload", queryParams, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var link Link
+	if err := json.Unmarshal(data, &link); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal upload URL: %w", err)
+	}
+
+	if link.Href == "" {
+		return nil, fmt.Errorf("failed to get upload URL")
+	}
+
+	return &link, nil
+}
+
+// readerSize returns the number of bytes left in r, or -1 if it is unknown.
+func readerSize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	case io.Seeker:
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+		if _, err := v.Seek(offset, io.SeekStart); err != nil {
+			return -1
+		}
+		return end - offset
+	}
+	return -1
+}
+
+// Download opens remotePath for streaming. The returned Resource holds the
+// metadata of the file; the caller must close the reader.
+func (c *Client) Download(ctx context.Context, remotePath string) (io.ReadCloser, *Resource, error) {
+	resource, err := c.GetMetaContext(ctx, remotePath, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	link, err := c.getDownloadLink(ctx, remotePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, err := c.openLink(ctx, link.Href, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c.verifying(body, resource), resource, nil
+}
+
+// DownloadTo copies the content of remotePath to w and returns the number of
+// bytes written.
+func (c *Client) DownloadTo(ctx context.Context, remotePath string, w io.Writer) (int64, error) {
+	var resource *Resource
+	if c.verifyChecksums {
+		var err error
+		if resource, err = c.GetMetaContext(ctx, remotePath, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	link, err := c.getDownloadLink(ctx, remotePath)
+	if err != nil {
+		return 0, err
+	}
+
+	body, err := c.openLink(ctx, link.Href, true)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	n, err := io.Copy(w, c.verifying(body, resource))
+	if err != nil {
+		return n, fmt.Errorf("download failed: %w", err)
+	}
+	return n, nil
+}
+
+// DownloadRange opens length bytes of remotePath starting at offset for
+// streaming; a negative length reads to the end of the file. The download
+// href is reused by later calls for the same file for up to linkTTL, and the
+// range is requested with If-Range against the version the href served
+// before, so a file that has changed since is read from a fresh href. The
+// caller must close the reader.
+func (c *Client) DownloadRange(ctx context.Context, remotePath string, offset, length int64) (io.ReadCloser, error) {
+	for refresh := false; ; refresh = true {
+		link, err := c.downloadLink(ctx, remotePath, refresh)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := c.openRangeIf(ctx, link.href, true, offset, length, link.validator)
+		var statusErr *TransferError
+		if err != nil && !refresh && errors.As(err, &statusErr) && isExpiredLinkStatus(statusErr.StatusCode) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if body.Validator != link.validator {
+			if link.validator != "" {
+				// The file has changed and the whole of it was sent.
+				c.links.forget(remotePath)
+			} else {
+				c.links.setValidator(remotePath, link.href, body.Validator)
+			}
+		}
+		if !body.Partial && offset > 0 {
+			// The server ignored the Range header.
+			if _, err := io.CopyN(io.Discard, body, offset); err != nil {
+				body.Close()
+				return nil, fmt.Errorf("download failed: %w", err)
+			}
+		}
+		if length < 0 {
+			return body, nil
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(body, length), body}, nil
+	}
+}
+
+// downloadLink returns the cached download href of remotePath, requesting a
+// new one when there is none younger than linkTTL or refresh is set.
+func (c *Client) downloadLink(ctx context.Context, remotePath string, refresh bool) (cachedLink, error) {
+	if link, ok := c.links.get(remotePath); ok && !refresh {
+		return link, nil
+	}
+
+	link, err := c.getDownloadLink(ctx, remotePath)
+	if err != nil {
+		return cachedLink{}, err
+	}
+	cached := cachedLink{href: link.Href, created: time.Now()}
+	c.links.put(remotePath, cached)
+	return cached, nil
+}
+
+// linkCache holds the download hrefs used by DownloadRange, so that reading
+// a file in many small ranges does not request a new href for each of them.
+type linkCache struct {
+	mu    sync.Mutex
+	links map[string]cachedLink
+}
+
+type cachedLink struct {
+	href string
+	// validator identifies the version of the file the href served, or is
+	// empty before the first response.
+	validator string
+	created   time.Time
+}
+
+func (l *linkCache) get(remotePath string) (cachedLink, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	link, ok := l.links[remotePath]
+	if !ok || time.Since(link.created) >= linkTTL {
+		return cachedLink{}, false
+	}
+	return link, true
+}
+
+func (l *linkCache) put(remotePath string, link cachedLink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.links == nil {
+		l.links = map[string]cachedLink{}
+	}
+	for p, cached := range l.links {
+		if time.Since(cached.created) >= linkTTL {
+			delete(l.links, p)
+		}
+	}
+	l.links[remotePath] = link
+}
+
+func (l *linkCache) setValidator(remotePath, href, validator string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if link, ok := l.links[remotePath]; ok && link.href == href {
+		link.validator = validator
+		l.links[remotePath] = link
+	}
+}
+
+func (l *linkCache) forget(remotePath string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.links, remotePath)
+}
+
+// DownloadPublic opens a public resource, or the file at path inside a public
+// folder, for streaming. The caller must close the reader.
+func (c *Client) DownloadPublic(ctx context.Context, publicKey string, path *string) (io.ReadCloser, error) {
+	var resource *Resource
+	if c.verifyChecksums {
+		var err error
+		if resource, err = c.getPublicMeta(ctx, publicKey, path); err != nil {
+			return nil, err
+		}
+	}
+
+	link, err := c.getPublicDownloadLink(ctx, publicKey, path)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.openLink(ctx, link.Href, false)
+	if err != nil {
+		return nil, err
+	}
+	return c.verifying(body, resource), nil
+}
+
+func (c *Client) DownloadPublicTo(ctx context.Context, publicKey string, path *string, w io.Writer) (int64, error) {
+	body, err := c.DownloadPublic(ctx, publicKey, path)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	n, err := io.Copy(w, body)
+	if err != nil {
+		return n, fmt.Errorf("download failed: %w", err)
+	}
+	return n, nil
+}
+
+func (c *Client) getDownloadLink(ctx context.Context, remotePath string) (*Link, error) {
+	queryParams := url.Values{}
+	queryParams.Set("path", remotePath)
+
+	data, err := c.request(ctx, "GET", "/resources/download", queryParams, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var link Link
+	if err := json.Unmarshal(data, &link); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal download URL: %w", err)
+	}
+
+	if link.Href == "" {
+		return nil, fmt.Errorf("failed to get download URL for: %s", remotePath)
+	}
+
+	return &link, nil
+}
+
+func (c *Client) getPublicDownloadLink(ctx context.Context, publicKey string, path *string) (*Link, error) {
+	queryParams := url.Values{}
+	queryParams.Set("public_key", publicKey)
+	if path != nil {
+		queryParams.Set("path", *path)
+	}
+
+	data, err := c.request(ctx, "GET", "/public/resources/download", queryParams, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var link Link
+	if err := json.Unmarshal(data, &link); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal download URL: %w", err)
+	}
+
+	if link.Href == "" {
+		return nil, fmt.Errorf("failed to get download URL for public resource")
+	}
+
+	return &link, nil
+}
+
+// openLink starts a GET of a download href. The transfer timeout and the
+// transfer limiter slot stay in effect until the returned body is closed.
+func (c *Client) openLink(ctx context.Context, href string, auth bool) (io.ReadCloser, error) {
+	return c.openRange(ctx, href, auth, 0, -1)
+}
+
+// rangeBody is a download body together with its position in the file.
+type rangeBody struct {
+	io.ReadCloser
+	// Offset is the position of the first byte of the body.
+	Offset int64
+	// Size is the size of the whole file, or -1 if it is not known.
+	Size int64
+	// Partial reports whether the server honored the Range header.
+	Partial bool
+	// Validator identifies the version of the file: its strong ETag, or
+	// its Last-Modified date if it has none. It is empty if the server sent
+	// neither.
+	Validator string
+}
+
+// openRange is like openLink but requests length bytes starting at offset.
+// A negative length means "until the end of the file". Servers that ignore
+// Range answer with the whole file, which is reported by Partial == false.
+func (c *Client) openRange(ctx context.Context, href string, auth bool, offset, length int64) (*rangeBody, error) {
+	return c.openRangeIf(ctx, href, auth, offset, length, "")
+}
+
+// openRangeIf is like openRange but sends ifRange as the If-Range header, so
+// that the server sends the whole file instead of the range if the file no
+// longer matches the validator.
+func (c *Client) openRangeIf(ctx context.Context, href string, auth bool, offset, length int64, ifRange string) (*rangeBody, error) {
+	ctx, cancel := c.withTimeout(ctx, c.transferTimeout)
+
+	release, err := c.transferLimiter.acquire(ctx)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+
+	resp, err := c.do(ctx, c.transferLimiter, replaySafe, func(ctx context.Context) (*http.Request, error) {
+		req, err := c.newRequest(ctx, "GET", href, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create download request: %w", err)
+		}
+
+		if auth {
+			if err := c.authorize(req); err != nil {
+				return nil, err
+			}
+		}
+		if length >= 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+		} else if offset > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+		if ifRange != "" && req.Header.Get("Range") != "" {
+			req.Header.Set("If-Range", ifRange)
+		}
+		return req, nil
+	})
+	if err != nil {
+		release()
+		cancel()
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+
+	body := &rangeBody{Size: resp.ContentLength, Validator: responseValidator(resp)}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusPartialContent:
+		start, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok {
+			drainBody(resp)
+			release()
+			cancel()
+			return nil, fmt.Errorf("download failed: invalid Content-Range %q", resp.Header.Get("Content-Range"))
+		}
+		body.Offset, body.Size, body.Partial = start, total, true
+	default:
+		drainBody(resp)
+		release()
+		cancel()
+		return nil, newTransferError("download", resp)
+	}
+
+	body.ReadCloser = &transferBody{
+		Reader:  newProgressReader(resp.Body, body.Size, body.Offset, c.progressFunc(ctx)),
+		body:    resp.Body,
+		release: release,
+		cancel:  cancel,
+	}
+	return body, nil
+}
+
+// responseValidator returns the value to send as If-Range to ask for more
+// of the same version of a file. Weak ETags may not be used for that.
+func responseValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// parseContentRange parses a "bytes first-last/total" header. total is -1
+// when the server reports it as "*".
+func parseContentRange(value string) (start, total int64, ok bool) {
+	var last int64
+	if _, err := fmt.Sscanf(value, "bytes %d-%d/", &start, &last); err != nil {
+		return 0, 0, false
+	}
+
+	total, ok = contentRangeTotal(value)
+	if !ok {
+		return 0, 0, false
+	}
+	return start, total, true
+}
+
+// contentRangeTotal returns the complete length from a Content-Range header,
+// including the "bytes */total" form sent with 416 responses.
+func contentRangeTotal(value string) (int64, bool) {
+	slash := strings.LastIndexByte(value, '/')
+	if slash < 0 {
+		return 0, false
+	}
+	if value[slash+1:] == "*" {
+		return -1, true
+	}
+	total, err := strconv.ParseInt(value[slash+1:], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return total, true
+}
+
+// TransferError is returned when an upload or download href answers with an
+// unexpected status. Like *APIError it can be tested with errors.Is against
+// ErrInsufficientStorage and the other sentinel errors.
+type TransferError struct {
+	// Op is "upload" or "download".
+	Op         string
+	StatusCode int
+	// RequestID identifies the failed request in the API logs, if the
+	// response carried one.
+	RequestID string
+	// ContentRange is the Content-Range header of the response, which
+	// reports the file size when a range could not be satisfied.
+	ContentRange string
+}
+
+func (e *TransferError) Error() string {
+	return fmt.Sprintf("%s failed with status: %d", e.Op, e.StatusCode)
+}
+
+func newTransferError(op string, resp *http.Response) *TransferError {
+	return &TransferError{
+		Op:           op,
+		StatusCode:   resp.StatusCode,
+		RequestID:    requestID(resp.Header),
+		ContentRange: resp.Header.Get("Content-Range"),
+	}
+}
+
+type transferBody struct {
+	io.Reader
+	body    io.Closer
+	release func()
+	cancel  context.CancelFunc
+}
+
+func (b *transferBody) Close() error {
+	err := b.body.Close()
+	b.release()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,214 @@
+package yandexdisk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type uploadRecorder struct {
+	server        *httptest.Server
+	puts          int32
+	failFirstPut  bool
+	putStatus     int
+	body          string
+	contentLength int64
+	overwrite     string
+}
+
+func newUploadServer(t *testing.T) *uploadRecorder {
+	rec := &uploadRecorder{}
+	rec.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			rec.overwrite = r.URL.Query().Get("overwrite")
+			w.Write([]byte(`{"href": "` + rec.server.URL + `/upload-target", "method": "PUT"}`))
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			if atomic.AddInt32(&rec.puts, 1) == 1 && rec.failFirstPut {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			rec.body = string(data)
+			rec.contentLength = r.ContentLength
+			if rec.putStatus != 0 {
+				w.WriteHeader(rec.putStatus)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	t.Cleanup(rec.server.Close)
+	return rec
+}
+
+func TestUploadKnownLength(t *testing.T) {
+	rec := newUploadServer(t)
+	client := NewClient("test-token", WithBaseURL(rec.server.URL))
+
+	result, err := client.Upload(context.Background(), strings.NewReader("hello"), "/disk/hello.txt", &UploadOptions{Overwrite: true})
+
+	assert.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "hello", rec.body)
+	assert.Equal(t, int64(5), rec.contentLength)
+	assert.Equal(t, "true", rec.overwrite)
+}
+
+func TestUploadUnknownLength(t *testing.T) {
+	rec := newUploadServer(t)
+	client := NewClient("test-token", WithBaseURL(rec.server.URL))
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("generated "))
+		pw.Write([]byte("data"))
+		pw.Close()
+	}()
+
+	result, err := client.Upload(context.Background(), pr, "/disk/generated.txt", nil)
+
+	assert.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "generated data", rec.body)
+	assert.Equal(t, int64(-1), rec.contentLength)
+	assert.Equal(t, "false", rec.overwrite)
+}
+
+func TestUploadRewindsSeekableReader(t *testing.T) {
+	rec := newUploadServer(t)
+	rec.failFirstPut = true
+	client := NewClient("test-token", WithBaseURL(rec.server.URL), WithRetryPolicy(testRetryPolicy))
+
+	result, err := client.Upload(context.Background(), strings.NewReader("retried"), "/disk/file.txt", nil)
+
+	assert.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "retried", rec.body)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&rec.puts))
+}
+
+func TestUploadDoesNotReplayStream(t *testing.T) {
+	rec := newUploadServer(t)
+	rec.failFirstPut = true
+	client := NewClient("test-token", WithBaseURL(rec.server.URL), WithRetryPolicy(testRetryPolicy))
+
+	result, err := client.Upload(context.Background(), io.MultiReader(strings.NewReader("once")), "/disk/file.txt", nil)
+
+	assert.Nil(t, result)
+	var transferErr *TransferError
+	assert.True(t, errors.As(err, &transferErr))
+	assert.Equal(t, "upload", transferErr.Op)
+	assert.Equal(t, http.StatusServiceUnavailable, transferErr.StatusCode)
+	assert.True(t, IsRetryable(err))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&rec.puts))
+}
+
+func TestUploadStatus(t *testing.T) {
+	rec := newUploadServer(t)
+	client := NewClient("test-token", WithBaseURL(rec.server.URL))
+
+	rec.putStatus = http.StatusAccepted
+	result, err := client.Upload(context.Background(), strings.NewReader("queued"), "/disk/file.txt", nil)
+	assert.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, http.StatusAccepted, result.Status)
+
+	rec.putStatus = http.StatusInsufficientStorage
+	_, err = client.Upload(context.Background(), strings.NewReader("too large"), "/disk/file.txt", nil)
+	assert.ErrorIs(t, err, ErrInsufficientStorage)
+	assert.False(t, IsRetryable(err))
+}
+
+func TestReaderSize(t *testing.T) {
+	assert.Equal(t, int64(3), readerSize(strings.NewReader("abc")))
+	assert.Equal(t, int64(-1), readerSize(io.MultiReader()))
+}
+
+func TestDownload(t *testing.T) {
+	server := newFileServer(t, "file content")
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	body, resource, err := client.Download(context.Background(), "/disk/file.txt")
+	assert.NoError(t, err)
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Equal(t, "file content", string(data))
+	assert.Equal(t, "file.txt", resource.Name)
+	assert.Equal(t, int64(12), resource.Size)
+}
+
+func TestDownloadTo(t *testing.T) {
+	server := newFileServer(t, "file content")
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	var buf bytes.Buffer
+	n, err := client.DownloadTo(context.Background(), "/disk/file.txt", &buf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), n)
+	assert.Equal(t, "file content", buf.String())
+}
+
+func TestDownloadRange(t *testing.T) {
+	server := newFileServer(t, "file content")
+	server.expiredURLs = 1
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	read := func(offset, length int64) string {
+		body, err := client.DownloadRange(context.Background(), "/disk/file.txt", offset, length)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		defer body.Close()
+		data, err := io.ReadAll(body)
+		assert.NoError(t, err)
+		return string(data)
+	}
+
+	// The expired href is replaced once and then reused.
+	assert.Equal(t, "file", read(0, 4))
+	assert.Equal(t, "content", read(5, -1))
+	assert.Equal(t, 2, server.links)
+	assert.Equal(t, []string{"bytes=0-3", "bytes=5-"}, server.ranges)
+
+	// A changed file fails the If-Range check and is read from a new href.
+	server.mu.Lock()
+	server.content = []byte("new content")
+	server.mu.Unlock()
+	assert.Equal(t, "content", read(4, 7))
+	assert.Equal(t, "new", read(0, 3))
+	assert.Equal(t, 3, server.links)
+}
+
+func TestDownloadPublicTo(t *testing.T) {
+	server := newFileServer(t, "public content")
+	client := NewClient("test-token", WithBaseURL(server.URL))
+
+	var buf bytes.Buffer
+	_, err := client.DownloadPublicTo(context.Background(), "https://yadi.sk/d/abc", nil, &buf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "public content", buf.String())
+}
+
+func TestDownloadReleasesTransferSlot(t *testing.T) {
+	server := newFileServer(t, "file content")
+	client := NewClient("test-token", WithBaseURL(server.URL), WithTransferLimits(Limits{MaxConcurrent: 1}))
+
+	for i := 0; i < 3; i++ {
+		body, _, err := client.Download(context.Background(), "/disk/file.txt")
+		assert.NoError(t, err)
+		body.Close()
+	}
+}
@@ -0,0 +1,182 @@
+package yandexdisk
+
+import (
+	"context"
+	"io/fs"
+	"path"
+)
+
+// SkipDir and SkipAll are returned from a WalkFunc to skip a directory or
+// stop the walk. They are the same values as fs.SkipDir and fs.SkipAll.
+var (
+	SkipDir = fs.SkipDir
+	SkipAll = fs.SkipAll
+)
+
+const DefaultWalkConcurrency = 4
+
+// WalkFunc is called by Walk for every resource in the tree, with path built
+// from root and the names of the entries below it.
+//
+// As with filepath.WalkDir, a directory is first reported with a nil error
+// before it is listed. If listing it fails the function is called a second
+// time with the error. Returning SkipDir from a directory skips its contents;
+// returning it from a file skips the remaining entries of its directory.
+// Returning SkipAll stops the walk, and any other error aborts it.
+type WalkFunc func(path string, resource *Resource, err error) error
+
+type WalkOptions struct {
+	// Concurrency bounds the number of directory listings requested at once,
+	// DefaultWalkConcurrency if zero.
+	Concurrency int
+	// PageSize is the number of entries requested per listing page,
+	// DefaultPageSize if zero.
+	PageSize int
+}
+
+// Walk walks the tree rooted at root in depth-first order, calling fn for
+// each resource with default options.
+func (c *Client) Walk(ctx context.Context, root string, fn WalkFunc) error {
+	return c.WalkWithOptions(ctx, root, fn, nil)
+}
+
+// WalkWithOptions walks the tree rooted at root. fn is called sequentially in
+// the order the API lists entries, while the listings of upcoming
+// directories are fetched concurrently.
+func (c *Client) WalkWithOptions(ctx context.Context, root string, fn WalkFunc, opts *WalkOptions) error {
+	return Walk(ctx, c, root, fn, opts)
+}
+
+// Walk walks the tree rooted at root on d like Client.WalkWithOptions. opts
+// may be nil.
+func Walk(ctx context.Context, d MetaAPI, root string, fn WalkFunc, opts *WalkOptions) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := &walker{disk: d, fn: fn, concurrency: DefaultWalkConcurrency, pageSize: DefaultPageSize}
+	if opts != nil && opts.Concurrency > 0 {
+		w.concurrency = opts.Concurrency
+	}
+	if opts != nil && opts.PageSize > 0 {
+		w.pageSize = opts.PageSize
+	}
+	w.sem = make(chan struct{}, w.concurrency)
+
+	resource, err := d.GetMetaContext(ctx, root, nil)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = w.walk(ctx, root, resource, nil)
+	}
+	if err == SkipDir || err == SkipAll {
+		return nil
+	}
+	return err
+}
+
+type walker struct {
+	disk        MetaAPI
+	fn          WalkFunc
+	concurrency int
+	pageSize    int
+	sem         chan struct{}
+}
+
+// walk reports resource and, for directories, everything below it. l is the
+// listing of the directory if it was already started.
+func (w *walker) walk(ctx context.Context, p string, resource *Resource, l *listing) error {
+	if err := w.fn(p, resource, nil); err != nil || !resource.IsDir() {
+		if l != nil {
+			l.cancel()
+		}
+		return err
+	}
+
+	if l == nil {
+		l = w.list(ctx, p)
+	}
+	entries, err := l.wait()
+	if err != nil {
+		if err := w.fn(p, resource, err); err != nil && err != SkipDir {
+			return err
+		}
+		return nil
+	}
+
+	// Listings are started for at most w.concurrency directories ahead of
+	// the entry being walked.
+	listings := make([]*listing, len(entries))
+	next, ahead := 0, 0
+	for i := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		for next < len(entries) && ahead < w.concurrency {
+			if entries[next].IsDir() {
+				listings[next] = w.list(ctx, path.Join(p, entries[next].Name))
+				ahead++
+			}
+			next++
+		}
+		if listings[i] != nil {
+			ahead--
+		}
+
+		entry := &entries[i]
+		if err := w.walk(ctx, path.Join(p, entry.Name), entry, listings[i]); err != nil {
+			if err == SkipDir && entry.IsDir() {
+				continue
+			}
+			for _, l := range listings[i+1 : next] {
+				if l != nil {
+					l.cancel()
+				}
+			}
+			if err == SkipDir {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// listing is the complete list of entries of a directory, fetched in the
+// background.
+type listing struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	entries []Resource
+	err     error
+}
+
+func (w *walker) list(ctx context.Context, p string) *listing {
+	ctx, cancel := context.WithCancel(ctx)
+	l := &listing{done: make(chan struct{}), cancel: cancel}
+
+	go func() {
+		defer close(l.done)
+		select {
+		case w.sem <- struct{}{}:
+			defer func() { <-w.sem }()
+		case <-ctx.Done():
+			l.err = ctx.Err()
+			return
+		}
+
+		it := IterDir(ctx, w.disk, p, &IterOptions{PageSize: w.pageSize})
+		defer it.Close()
+		for it.Next() {
+			l.entries = append(l.entries, it.Resource())
+		}
+		l.err = it.Err()
+	}()
+	return l
+}
+
+func (l *listing) wait() ([]Resource, error) {
+	<-l.done
+	l.cancel()
+	return l.entries, l.err
+}
@@ -0,0 +1,116 @@
+package yandexdisk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTreeServer serves GetMeta for the directories in tree, which maps a
+// directory path to its entries; names ending in "/" are directories.
+func newTreeServer(t *testing.T, tree map[string][]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := r.URL.Query().Get("path")
+		names, ok := tree[p]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not found", "error": "DiskNotFoundError"}`))
+			return
+		}
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if limit == 0 {
+			limit = 20
+		}
+
+		dir := Resource{Path: p, Type: "dir", Embedded: &Embedded{Path: p, Limit: limit, Offset: offset, Total: len(names), Items: []Resource{}}}
+		for i := offset; i < len(names) && i < offset+limit; i++ {
+			entry := Resource{Name: strings.TrimSuffix(names[i], "/"), Type: "file"}
+			if strings.HasSuffix(names[i], "/") {
+				entry.Type = "dir"
+			}
+			dir.Embedded.Items = append(dir.Embedded.Items, entry)
+		}
+		json.NewEncoder(w).Encode(dir)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+var testTree = map[string][]string{
+	"/disk":      {"a/", "b/", "c.txt"},
+	"/disk/a":    {"a1.txt", "a2.txt", "a3/"},
+	"/disk/a/a3": {"deep.txt"},
+	"/disk/b":    {"b1.txt"},
+}
+
+func TestWalk(t *testing.T) {
+	client := NewClient("test-token", WithBaseURL(newTreeServer(t, testTree).URL))
+
+	var visited []string
+	err := client.WalkWithOptions(context.Background(), "/disk", func(path string, r *Resource, err error) error {
+		visited = append(visited, path)
+		return err
+	}, &WalkOptions{PageSize: 1, Concurrency: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/disk",
+		"/disk/a", "/disk/a/a1.txt", "/disk/a/a2.txt", "/disk/a/a3", "/disk/a/a3/deep.txt",
+		"/disk/b", "/disk/b/b1.txt",
+		"/disk/c.txt",
+	}, visited)
+}
+
+func TestWalkSkipDir(t *testing.T) {
+	client := NewClient("test-token", WithBaseURL(newTreeServer(t, testTree).URL))
+
+	var visited []string
+	err := client.Walk(context.Background(), "/disk", func(path string, r *Resource, err error) error {
+		visited = append(visited, path)
+		switch path {
+		case "/disk/a":
+			return SkipDir
+		case "/disk/b/b1.txt":
+			return SkipAll
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/disk", "/disk/a", "/disk/b", "/disk/b/b1.txt"}, visited)
+}
+
+func TestWalkReportsListingError(t *testing.T) {
+	tree := map[string][]string{"/disk": {"missing/", "file.txt"}}
+	client := NewClient("test-token", WithBaseURL(newTreeServer(t, tree).URL))
+
+	var visited []string
+	err := client.Walk(context.Background(), "/disk", func(path string, r *Resource, err error) error {
+		if err != nil {
+			visited = append(visited, path+" error")
+			return nil
+		}
+		visited = append(visited, path)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/disk", "/disk/missing", "/disk/missing error", "/disk/file.txt"}, visited)
+
+	stop := errors.New("stop")
+	err = client.Walk(context.Background(), "/nowhere", func(path string, r *Resource, err error) error {
+		assert.Nil(t, r)
+		assert.Error(t, err)
+		return stop
+	})
+	assert.Equal(t, stop, err)
+}
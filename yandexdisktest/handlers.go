@@ -0,0 +1,738 @@
+package yandexdisktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiError is an error response in the format of the API.
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+var (
+	errUnauthorized = &apiError{http.StatusUnauthorized, "UnauthorizedError", "Unauthorized"}
+	errNotFound     = &apiError{http.StatusNotFound, "DiskNotFoundError", "Resource not found."}
+	errExists       = &apiError{http.StatusConflict, "DiskResourceAlreadyExistsError", "Resource already exists."}
+	errDirExists    = &apiError{http.StatusConflict, "DiskPathPointsToExistentDirectoryError", "Specified path points to an existing directory."}
+	errNoParent     = &apiError{http.StatusConflict, "DiskPathDoesntExistsError", "Specified path doesn't exist."}
+	errInvalidPath  = &apiError{http.StatusBadRequest, "FieldValidationError", "Specified path is invalid for this operation."}
+)
+
+func writeError(w http.ResponseWriter, err *apiError) {
+	writeJSON(w, err.status, map[string]string{
+		"message":     err.message,
+		"description": err.message,
+		"error":       err.code,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	if v == nil {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+type link struct {
+	Href      string `json:"href"`
+	Method    string `json:"method"`
+	Templated bool   `json:"templated"`
+}
+
+// metaLink links to the metadata of the resource at p, as the API does in
+// answers to modifying requests.
+func (s *Server) metaLink(p string) link {
+	return link{Href: s.URL + "/resources?path=" + url.QueryEscape("disk:"+p), Method: "GET"}
+}
+
+type upload struct {
+	path    string
+	expired bool
+}
+
+type download struct {
+	path    string
+	expired bool
+}
+
+type operation struct {
+	status string
+	polls  int
+	work   func() *apiError
+}
+
+// poll returns the status of the operation, performing its work once the
+// in-progress polls are used up.
+func (o *operation) poll() string {
+	if o.status == "in-progress" && o.work != nil {
+		if o.polls > 0 {
+			o.polls--
+			return o.status
+		}
+		o.status = "success"
+		if err := o.work(); err != nil {
+			o.status = "failed"
+		}
+		o.work = nil
+	}
+	return o.status
+}
+
+// finish performs work now and responds with status and v, or with
+// WithAsyncOperations starts an operation for it and responds with 202.
+func (s *Server) finish(w http.ResponseWriter, work func() *apiError, status int, v interface{}) *apiError {
+	if !s.async {
+		if err := work(); err != nil {
+			return err
+		}
+		writeJSON(w, status, v)
+		return nil
+	}
+	id := newID()
+	s.operations[id] = &operation{status: "in-progress", polls: s.asyncPolls, work: work}
+	writeJSON(w, http.StatusAccepted, link{Href: s.URL + "/operations/" + id, Method: "GET"})
+	return nil
+}
+
+func intParam(q url.Values, name string, def int) int {
+	if n, err := strconv.Atoi(q.Get(name)); err == nil && n >= 0 {
+		return n
+	}
+	return def
+}
+
+func boolParam(q url.Values, name string) bool {
+	b, _ := strconv.ParseBool(q.Get(name))
+	return b
+}
+
+// page returns the part of paths selected by the limit and offset
+// parameters.
+func page(paths []string, q url.Values) (items []string, limit, offset int) {
+	limit = intParam(q, "limit", 20)
+	offset = min(intParam(q, "offset", 0), len(paths))
+	return paths[offset:min(offset+limit, len(paths))], limit, offset
+}
+
+func baseName(p, root string) string {
+	if p == "/" {
+		return root
+	}
+	return path.Base(p)
+}
+
+func (s *Server) diskResource(p string) resource {
+	return newResource(s.disk[p], baseName(p, "disk"), "disk:"+p)
+}
+
+func (s *Server) trashResource(p string) resource {
+	return newResource(s.trash[p], baseName(p, "trash"), "trash:"+p)
+}
+
+// listing adds the page of children of the folder res describes, using
+// describe for each child of p in t.
+func listing(res *resource, t tree, p string, q url.Values, describe func(string) resource) {
+	if !t[p].dir {
+		return
+	}
+	sortBy := q.Get("sort")
+	if sortBy == "" {
+		sortBy = "name"
+	}
+	children := t.children(p)
+	t.sortPaths(children, sortBy)
+	paths, limit, offset := page(children, q)
+	res.Embedded = &embedded{
+		Sort:   sortBy,
+		Path:   res.Path,
+		Items:  []resource{},
+		Limit:  limit,
+		Offset: offset,
+		Total:  len(children),
+	}
+	for _, child := range paths {
+		res.Embedded.Items = append(res.Embedded.Items, describe(child))
+	}
+}
+
+func (s *Server) handleDiskInfo(w http.ResponseWriter, r *http.Request) *apiError {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"total_space":   s.totalSpace,
+		"used_space":    s.disk.size() + s.trash.size(),
+		"trash_size":    s.trash.size(),
+		"max_file_size": int64(1 << 30),
+		"is_paid":       false,
+		"revision":      s.revision,
+		"system_folders": map[string]string{
+			"downloads": "disk:/Downloads",
+		},
+		"user": map[string]string{
+			"login":        "test",
+			"display_name": "Test User",
+			"uid":          "1",
+		},
+	})
+	return nil
+}
+
+func (s *Server) handleGetResource(w http.ResponseWriter, r *http.Request) *apiError {
+	q := r.URL.Query()
+	p := diskPath(q.Get("path"))
+	if _, ok := s.disk[p]; !ok {
+		return errNotFound
+	}
+	res := s.diskResource(p)
+	listing(&res, s.disk, p, q, s.diskResource)
+	writeJSON(w, http.StatusOK, res)
+	return nil
+}
+
+// checkParent reports whether the parent folder of p exists.
+func (s *Server) checkParent(p string) *apiError {
+	if parent, ok := s.disk[parentPath(p)]; !ok || !parent.dir {
+		return errNoParent
+	}
+	return nil
+}
+
+func (s *Server) handleCreateFolder(w http.ResponseWriter, r *http.Request) *apiError {
+	p := diskPath(r.URL.Query().Get("path"))
+	if _, ok := s.disk[p]; ok {
+		return errDirExists
+	}
+	if err := s.checkParent(p); err != nil {
+		return err
+	}
+	s.mkdir(p)
+	writeJSON(w, http.StatusCreated, s.metaLink(p))
+	return nil
+}
+
+func (s *Server) handlePatchResource(w http.ResponseWriter, r *http.Request) *apiError {
+	var body struct {
+		Path             string                 `json:"path"`
+		CustomProperties map[string]interface{} `json:"custom_properties"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return &apiError{http.StatusBadRequest, "FieldValidationError", "Invalid request body."}
+	}
+	p := r.URL.Query().Get("path")
+	if p == "" {
+		p = body.Path
+	}
+	p = diskPath(p)
+	n, ok := s.disk[p]
+	if !ok {
+		return errNotFound
+	}
+
+	for k, v := range body.CustomProperties {
+		if v == nil {
+			delete(n.props, k)
+			continue
+		}
+		if n.props == nil {
+			n.props = map[string]interface{}{}
+		}
+		n.props[k] = v
+	}
+	s.touch(n)
+	writeJSON(w, http.StatusOK, s.diskResource(p))
+	return nil
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) *apiError {
+	q := r.URL.Query()
+	p := diskPath(q.Get("path"))
+	if p == "/" {
+		return errInvalidPath
+	}
+	if _, ok := s.disk[p]; !ok {
+		return errNotFound
+	}
+	permanently := boolParam(q, "permanently")
+
+	return s.finish(w, func() *apiError {
+		if _, ok := s.disk[p]; !ok {
+			return errNotFound
+		}
+		sub := s.disk.detach(p)
+		if !permanently {
+			s.moveToTrash(p, sub)
+		}
+		s.revision++
+		return nil
+	}, http.StatusNoContent, nil)
+}
+
+// moveToTrash adds the subtree deleted from p to the Trash, renaming it if
+// an entry with the same name already exists.
+func (s *Server) moveToTrash(p string, sub tree) {
+	name := "/" + path.Base(p)
+	if _, ok := s.trash[name]; ok {
+		name += "_" + newID()[:8]
+	}
+	for _, n := range sub {
+		n.publicKey = ""
+	}
+	sub["/"].originPath = p
+	sub["/"].deleted = time.Now()
+	s.trash.attach(name, sub)
+}
+
+type filesList struct {
+	Items  []resource `json:"items"`
+	Limit  int        `json:"limit"`
+	Offset int        `json:"offset"`
+}
+
+func (s *Server) writeFiles(w http.ResponseWriter, paths []string, q url.Values) {
+	paths, limit, offset := page(paths, q)
+	list := filesList{Items: []resource{}, Limit: limit, Offset: offset}
+	for _, p := range paths {
+		list.Items = append(list.Items, s.diskResource(p))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleFiles(w http.ResponseWriter, r *http.Request) *apiError {
+	q := r.URL.Query()
+	paths := s.disk.files()
+	if types := q.Get("media_type"); types != "" {
+		var filtered []string
+		for _, p := range paths {
+			mediaType := mediaType(mimeType(p))
+			for _, t := range strings.Split(types, ",") {
+				if t == mediaType {
+					filtered = append(filtered, p)
+					break
+				}
+			}
+		}
+		paths = filtered
+	}
+	if sortBy := q.Get("sort"); sortBy != "" {
+		s.disk.sortPaths(paths, sortBy)
+	}
+	s.writeFiles(w, paths, q)
+	return nil
+}
+
+func (s *Server) handleLastUploaded(w http.ResponseWriter, r *http.Request) *apiError {
+	paths := s.disk.files()
+	s.disk.sortPaths(paths, "-created")
+	s.writeFiles(w, paths, r.URL.Query())
+	return nil
+}
+
+func (s *Server) handlePublished(w http.ResponseWriter, r *http.Request) *apiError {
+	q := r.URL.Query()
+	var paths []string
+	for p, n := range s.disk {
+		if n.publicKey == "" {
+			continue
+		}
+		if t := q.Get("type"); t == "dir" && !n.dir || t == "file" && n.dir {
+			continue
+		}
+		paths = append(paths, p)
+	}
+	s.disk.sortPaths(paths, "path")
+	s.writeFiles(w, paths, q)
+	return nil
+}
+
+func (s *Server) handleCopy(w http.ResponseWriter, r *http.Request) *apiError {
+	return s.transfer(w, r, false)
+}
+
+func (s *Server) handleMove(w http.ResponseWriter, r *http.Request) *apiError {
+	return s.transfer(w, r, true)
+}
+
+// transfer copies or moves the resource at "from" to "path".
+func (s *Server) transfer(w http.ResponseWriter, r *http.Request, move bool) *apiError {
+	q := r.URL.Query()
+	from, to := diskPath(q.Get("from")), diskPath(q.Get("path"))
+	overwrite := boolParam(q, "overwrite")
+
+	check := func() *apiError {
+		if _, ok := s.disk[from]; !ok {
+			return errNotFound
+		}
+		if from == "/" || to == "/" || to == from || strings.HasPrefix(to, from+"/") {
+			return errInvalidPath
+		}
+		if _, ok := s.disk[to]; ok && !overwrite {
+			return errExists
+		}
+		return s.checkParent(to)
+	}
+	if err := check(); err != nil {
+		return err
+	}
+
+	return s.finish(w, func() *apiError {
+		if err := check(); err != nil {
+			return err
+		}
+		s.disk.detach(to)
+		var sub tree
+		if move {
+			sub = s.disk.detach(from)
+		} else {
+			sub = s.disk.clone(from)
+		}
+		s.disk.attach(to, sub)
+		s.touch(s.disk[to])
+		return nil
+	}, http.StatusCreated, s.metaLink(to))
+}
+
+func (s *Server) handlePublish(w http.ResponseWriter, r *http.Request) *apiError {
+	p := diskPath(r.URL.Query().Get("path"))
+	n, ok := s.disk[p]
+	if !ok {
+		return errNotFound
+	}
+	if n.publicKey == "" {
+		n.publicKey = newID()
+		s.touch(n)
+	}
+	writeJSON(w, http.StatusOK, s.metaLink(p))
+	return nil
+}
+
+func (s *Server) handleUnpublish(w http.ResponseWriter, r *http.Request) *apiError {
+	p := diskPath(r.URL.Query().Get("path"))
+	n, ok := s.disk[p]
+	if !ok {
+		return errNotFound
+	}
+	if n.publicKey != "" {
+		n.publicKey = ""
+		s.touch(n)
+	}
+	writeJSON(w, http.StatusOK, s.metaLink(p))
+	return nil
+}
+
+func (s *Server) handleUploadLink(w http.ResponseWriter, r *http.Request) *apiError {
+	q := r.URL.Query()
+	p := diskPath(q.Get("path"))
+	if n, ok := s.disk[p]; ok {
+		if n.dir {
+			return errDirExists
+		}
+		if !boolParam(q, "overwrite") {
+			return errExists
+		}
+	}
+	if err := s.checkParent(p); err != nil {
+		return err
+	}
+
+	id := newID()
+	s.uploads[id] = upload{path: p}
+	writeJSON(w, http.StatusOK, link{Href: s.URL + "/upload/" + id, Method: "PUT"})
+	return nil
+}
+
+// handleUpload stores the body of a PUT request to an upload href.
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "PUT" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.uploads[strings.TrimPrefix(r.URL.Path, "/upload/")]
+	switch {
+	case !ok:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	case u.expired:
+		w.WriteHeader(http.StatusGone)
+		return
+	case s.checkParent(u.path) != nil:
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	used := s.disk.size() + s.trash.size()
+	if n, ok := s.disk[u.path]; ok {
+		used -= int64(len(n.data))
+	}
+	if used+int64(len(data)) > s.totalSpace {
+		w.WriteHeader(http.StatusInsufficientStorage)
+		return
+	}
+
+	s.writeFile(u.path, data)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) handleUploadFromURL(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	p := diskPath(q.Get("path"))
+	fileURL := q.Get("url")
+
+	s.mu.Lock()
+	err := s.checkParent(p)
+	if _, ok := s.disk[p]; ok {
+		err = errExists
+	}
+	if err != nil {
+		s.mu.Unlock()
+		writeError(w, err)
+		return
+	}
+	id := newID()
+	op := &operation{status: "in-progress"}
+	s.operations[id] = op
+	s.mu.Unlock()
+
+	go func() {
+		data, err := fetch(fileURL)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if err != nil || s.checkParent(p) != nil {
+			op.status = "failed"
+			return
+		}
+		s.writeFile(p, data)
+		op.status = "success"
+	}()
+
+	writeJSON(w, http.StatusAccepted, link{Href: s.URL + "/operations/" + id, Method: "GET"})
+}
+
+func fetch(fileURL string) ([]byte, error) {
+	resp, err := http.Get(fileURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (s *Server) downloadLink(w http.ResponseWriter, p string) *apiError {
+	n, ok := s.disk[p]
+	if !ok {
+		return errNotFound
+	}
+	if n.dir {
+		return &apiError{http.StatusNotImplemented, "NotImplementedError", "Downloading folders is not supported by the fake server."}
+	}
+	id := newID()
+	s.downloads[id] = download{path: p}
+	writeJSON(w, http.StatusOK, link{Href: s.URL + "/download/" + id, Method: "GET"})
+	return nil
+}
+
+func (s *Server) handleDownloadLink(w http.ResponseWriter, r *http.Request) *apiError {
+	return s.downloadLink(w, diskPath(r.URL.Query().Get("path")))
+}
+
+// handleDownload serves the file behind a download href, including Range
+// requests.
+func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	d, ok := s.downloads[strings.TrimPrefix(r.URL.Path, "/download/")]
+	n, exists := s.disk[d.path]
+	var file node
+	if exists {
+		// Stored data is replaced rather than modified in place, so it
+		// can be served without the lock.
+		file = *n
+	}
+	s.mu.Unlock()
+
+	switch {
+	case d.expired:
+		w.WriteHeader(http.StatusGone)
+	case !ok || !exists || file.dir:
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.Header().Set("ETag", `"`+file.md5+`"`)
+		http.ServeContent(w, r, path.Base(d.path), file.modified, bytes.NewReader(file.data))
+	}
+}
+
+// resolvePublic returns the disk path of the resource at sub inside the
+// resource published with key, which may also be given as a public URL.
+func (s *Server) resolvePublic(key, sub string) (root, p string, err *apiError) {
+	key = strings.TrimPrefix(key, publicURL(""))
+	for k, n := range s.disk {
+		if n.publicKey == key && key != "" {
+			root = k
+			break
+		}
+	}
+	if root == "" {
+		return "", "", errNotFound
+	}
+
+	p = root
+	if rel := path.Clean("/" + sub); rel != "/" {
+		p = root + rel
+	}
+	if _, ok := s.disk[p]; !ok {
+		return "", "", errNotFound
+	}
+	return root, p, nil
+}
+
+func (s *Server) handlePublicResource(w http.ResponseWriter, r *http.Request) *apiError {
+	q := r.URL.Query()
+	root, p, err := s.resolvePublic(q.Get("public_key"), q.Get("path"))
+	if err != nil {
+		return err
+	}
+
+	describe := func(p string) resource {
+		rel := "/" + strings.TrimPrefix(strings.TrimPrefix(p, root), "/")
+		res := newResource(s.disk[p], path.Base(p), rel)
+		res.PublicKey = s.disk[root].publicKey
+		res.PublicURL = publicURL(res.PublicKey)
+		return res
+	}
+	res := describe(p)
+	listing(&res, s.disk, p, q, describe)
+	writeJSON(w, http.StatusOK, res)
+	return nil
+}
+
+func (s *Server) handlePublicDownloadLink(w http.ResponseWriter, r *http.Request) *apiError {
+	q := r.URL.Query()
+	_, p, err := s.resolvePublic(q.Get("public_key"), q.Get("path"))
+	if err != nil {
+		return err
+	}
+	return s.downloadLink(w, p)
+}
+
+// handleSavePublic copies a public resource into the folder given by
+// "path", "/Downloads" by default.
+func (s *Server) handleSavePublic(w http.ResponseWriter, r *http.Request) *apiError {
+	q := r.URL.Query()
+	_, from, err := s.resolvePublic(q.Get("public_key"), "")
+	if err != nil {
+		return err
+	}
+
+	folder := "/Downloads"
+	if q.Get("path") != "" {
+		folder = diskPath(q.Get("path"))
+	}
+	name := q.Get("name")
+	if name == "" {
+		name = path.Base(from)
+	}
+	to := path.Join(folder, name)
+	if _, ok := s.disk[to]; ok {
+		return errExists
+	}
+
+	s.mkdirAll(folder)
+	s.disk.attach(to, s.disk.clone(from))
+	s.touch(s.disk[to])
+	writeJSON(w, http.StatusCreated, s.metaLink(to))
+	return nil
+}
+
+func (s *Server) handleGetTrash(w http.ResponseWriter, r *http.Request) *apiError {
+	q := r.URL.Query()
+	p := trashPath(q.Get("path"))
+	if _, ok := s.trash[p]; !ok {
+		return errNotFound
+	}
+	res := s.trashResource(p)
+	listing(&res, s.trash, p, q, s.trashResource)
+	writeJSON(w, http.StatusOK, res)
+	return nil
+}
+
+func (s *Server) handleClearTrash(w http.ResponseWriter, r *http.Request) *apiError {
+	q := r.URL.Query()
+	p := trashPath(q.Get("path"))
+	if _, ok := s.trash[p]; !ok {
+		return errNotFound
+	}
+
+	return s.finish(w, func() *apiError {
+		if p == "/" {
+			s.trash = newTree()
+		} else {
+			s.trash.detach(p)
+		}
+		s.revision++
+		return nil
+	}, http.StatusNoContent, nil)
+}
+
+// handleRestore restores a top-level Trash entry to its original location,
+// optionally under a new name.
+func (s *Server) handleRestore(w http.ResponseWriter, r *http.Request) *apiError {
+	q := r.URL.Query()
+	p := trashPath(q.Get("path"))
+	n, ok := s.trash[p]
+	if !ok || n.originPath == "" {
+		return errNotFound
+	}
+	to := n.originPath
+	if name := q.Get("name"); name != "" {
+		to = path.Join(parentPath(to), name)
+	}
+	overwrite := boolParam(q, "overwrite")
+	if _, ok := s.disk[to]; ok && !overwrite {
+		return errExists
+	}
+
+	return s.finish(w, func() *apiError {
+		if _, ok := s.trash[p]; !ok {
+			return errNotFound
+		}
+		s.disk.detach(to)
+		s.mkdirAll(parentPath(to))
+		sub := s.trash.detach(p)
+		sub["/"].originPath = ""
+		sub["/"].deleted = time.Time{}
+		s.disk.attach(to, sub)
+		s.touch(s.disk[to])
+		return nil
+	}, http.StatusCreated, s.metaLink(to))
+}
+
+func (s *Server) handleOperation(w http.ResponseWriter, r *http.Request) *apiError {
+	op, ok := s.operations[strings.TrimPrefix(r.URL.Path, "/operations/")]
+	if !ok {
+		return &apiError{http.StatusNotFound, "OperationNotFoundError", "Operation not found."}
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": op.poll()})
+	return nil
+}
@@ -0,0 +1,353 @@
+package yandexdisktest
+
+import (
+	"context"
+	"io"
+
+	yandexdisk "github.com/tigusigalpa/yandex-disk-go"
+)
+
+// MockDisk implements yandexdisk.Disk with a function field per method.
+// Set the fields a test needs; calling a method whose field is nil panics.
+//
+// MockDisk is written by hand on purpose: plain function fields need no mock
+// generator or expectation library in the consumer's build, and a test that
+// compares the fields with the interface by reflection fails as soon as a
+// method is added to yandexdisk.Disk without a field here.
+type MockDisk struct {
+	// MetaAPI
+	GetCapacityContextFunc        func(ctx context.Context) (*yandexdisk.DiskInfo, error)
+	GetMetaContextFunc            func(ctx context.Context, path string, params map[string]string) (*yandexdisk.Resource, error)
+	AddMetaContextFunc            func(ctx context.Context, path string, customProperties map[string]interface{}) (*yandexdisk.Resource, error)
+	GetAllFilesContextFunc        func(ctx context.Context, limit, offset int) (*yandexdisk.FilesList, error)
+	GetRecentUploadsContextFunc   func(ctx context.Context, limit, offset int) (*yandexdisk.FilesList, error)
+	GetRecentPublishedContextFunc func(ctx context.Context, limit, offset int) (*yandexdisk.FilesList, error)
+	CreateFolderContextFunc       func(ctx context.Context, path string) (*yandexdisk.Resource, error)
+	CopyContextFunc               func(ctx context.Context, fromPath, toPath string, overwrite bool) (*yandexdisk.Resource, error)
+	CopyAsyncFunc                 func(ctx context.Context, fromPath, toPath string, overwrite bool) (*yandexdisk.Operation, error)
+	MoveContextFunc               func(ctx context.Context, fromPath, toPath string, overwrite bool) (*yandexdisk.Resource, error)
+	MoveAsyncFunc                 func(ctx context.Context, fromPath, toPath string, overwrite bool) (*yandexdisk.Operation, error)
+	DeleteContextFunc             func(ctx context.Context, path string, permanently bool) error
+	DeleteAsyncFunc               func(ctx context.Context, path string, permanently bool) (*yandexdisk.Operation, error)
+	GetOperationStatusContextFunc func(ctx context.Context, operationID string) (*yandexdisk.Operation, error)
+	WaitOperationFunc             func(ctx context.Context, op *yandexdisk.Operation, opts *yandexdisk.WaitOptions) (*yandexdisk.Operation, error)
+
+	// TransferAPI
+	UploadFunc               func(ctx context.Context, r io.Reader, remotePath string, opts *yandexdisk.UploadOptions) (*yandexdisk.UploadResult, error)
+	UploadFileContextFunc    func(ctx context.Context, localFilePath, remotePath string, overwrite bool) (*yandexdisk.UploadResult, error)
+	UploadFromURLContextFunc func(ctx context.Context, fileURL, remotePath string, disableRedirects bool) (*yandexdisk.Operation, error)
+	DownloadFunc             func(ctx context.Context, remotePath string) (io.ReadCloser, *yandexdisk.Resource, error)
+	DownloadRangeFunc        func(ctx context.Context, remotePath string, offset, length int64) (io.ReadCloser, error)
+	DownloadToFunc           func(ctx context.Context, remotePath string, w io.Writer) (int64, error)
+	DownloadFileContextFunc  func(ctx context.Context, remotePath, localPath string) error
+	DownloadFileParallelFunc func(ctx context.Context, remotePath, localPath string, opts *yandexdisk.ParallelOptions) error
+
+	// TrashAPI
+	GetTrashContextFunc         func(ctx context.Context, path string, limit, offset int) (*yandexdisk.Resource, error)
+	RestoreFromTrashContextFunc func(ctx context.Context, path string, name *string, overwrite bool) (*yandexdisk.Resource, error)
+	ClearTrashContextFunc       func(ctx context.Context, path *string) error
+	ClearTrashAsyncFunc         func(ctx context.Context, path *string) (*yandexdisk.Operation, error)
+
+	// PublicAPI
+	PublishContextFunc                    func(ctx context.Context, path string) (*yandexdisk.Resource, error)
+	UnpublishContextFunc                  func(ctx context.Context, path string) (*yandexdisk.Resource, error)
+	GetPublicResourceMetaContextFunc      func(ctx context.Context, publicKey string, params map[string]string) (*yandexdisk.Resource, error)
+	DownloadPublicFunc                    func(ctx context.Context, publicKey string, path *string) (io.ReadCloser, error)
+	DownloadPublicToFunc                  func(ctx context.Context, publicKey string, path *string, w io.Writer) (int64, error)
+	DownloadPublicResourceContextFunc     func(ctx context.Context, publicKey, localPath string, path *string) error
+	SavePublicResourceContextFunc         func(ctx context.Context, publicKey string, name, path *string) (*yandexdisk.Resource, error)
+	GetAvailablePublicSettingsContextFunc func(ctx context.Context) (*yandexdisk.AvailablePublicSettings, error)
+	GetPublicSettingsContextFunc          func(ctx context.Context, path string, allowAddressAccess bool) (*yandexdisk.PublicSettings, error)
+	ChangePublicSettingsContextFunc       func(ctx context.Context, path string, settings *yandexdisk.PublicSettings) (*yandexdisk.PublicSettings, error)
+
+	// AdminAPI
+	GetPublicResourcesOwnedByUserContextFunc    func(ctx context.Context, userID, orgID string, limit, offset int) (*yandexdisk.FilesList, error)
+	GetPublicResourcesAccessedByUserContextFunc func(ctx context.Context, userID, orgID string, includeGroupAccess bool, limit int, iterationKey *string) (*yandexdisk.FilesList, error)
+	UnpublishUserResourceContextFunc            func(ctx context.Context, publicKey, userID, orgID string) error
+}
+
+var _ yandexdisk.Disk = (*MockDisk)(nil)
+
+func notSet(method string) string {
+	return "yandexdisktest: MockDisk." + method + "Func is not set"
+}
+
+func (m *MockDisk) GetCapacityContext(ctx context.Context) (*yandexdisk.DiskInfo, error) {
+	if m.GetCapacityContextFunc == nil {
+		panic(notSet("GetCapacityContext"))
+	}
+	return m.GetCapacityContextFunc(ctx)
+}
+
+func (m *MockDisk) GetMetaContext(ctx context.Context, path string, params map[string]string) (*yandexdisk.Resource, error) {
+	if m.GetMetaContextFunc == nil {
+		panic(notSet("GetMetaContext"))
+	}
+	return m.GetMetaContextFunc(ctx, path, params)
+}
+
+func (m *MockDisk) AddMetaContext(ctx context.Context, path string, customProperties map[string]interface{}) (*yandexdisk.Resource, error) {
+	if m.AddMetaContextFunc == nil {
+		panic(notSet("AddMetaContext"))
+	}
+	return m.AddMetaContextFunc(ctx, path, customProperties)
+}
+
+func (m *MockDisk) GetAllFilesContext(ctx context.Context, limit, offset int) (*yandexdisk.FilesList, error) {
+	if m.GetAllFilesContextFunc == nil {
+		panic(notSet("GetAllFilesContext"))
+	}
+	return m.GetAllFilesContextFunc(ctx, limit, offset)
+}
+
+func (m *MockDisk) GetRecentUploadsContext(ctx context.Context, limit, offset int) (*yandexdisk.FilesList, error) {
+	if m.GetRecentUploadsContextFunc == nil {
+		panic(notSet("GetRecentUploadsContext"))
+	}
+	return m.GetRecentUploadsContextFunc(ctx, limit, offset)
+}
+
+func (m *MockDisk) GetRecentPublishedContext(ctx context.Context, limit, offset int) (*yandexdisk.FilesList, error) {
+	if m.GetRecentPublishedContextFunc == nil {
+		panic(notSet("GetRecentPublishedContext"))
+	}
+	return m.GetRecentPublishedContextFunc(ctx, limit, offset)
+}
+
+func (m *MockDisk) CreateFolderContext(ctx context.Context, path string) (*yandexdisk.Resource, error) {
+	if m.CreateFolderContextFunc == nil {
+		panic(notSet("CreateFolderContext"))
+	}
+	return m.CreateFolderContextFunc(ctx, path)
+}
+
+func (m *MockDisk) CopyContext(ctx context.Context, fromPath, toPath string, overwrite bool) (*yandexdisk.Resource, error) {
+	if m.CopyContextFunc == nil {
+		panic(notSet("CopyContext"))
+	}
+	return m.CopyContextFunc(ctx, fromPath, toPath, overwrite)
+}
+
+func (m *MockDisk) CopyAsync(ctx context.Context, fromPath, toPath string, overwrite bool) (*yandexdisk.Operation, error) {
+	if m.CopyAsyncFunc == nil {
+		panic(notSet("CopyAsync"))
+	}
+	return m.CopyAsyncFunc(ctx, fromPath, toPath, overwrite)
+}
+
+func (m *MockDisk) MoveContext(ctx context.Context, fromPath, toPath string, overwrite bool) (*yandexdisk.Resource, error) {
+	if m.MoveContextFunc == nil {
+		panic(notSet("MoveContext"))
+	}
+	return m.MoveContextFunc(ctx, fromPath, toPath, overwrite)
+}
+
+func (m *MockDisk) MoveAsync(ctx context.Context, fromPath, toPath string, overwrite bool) (*yandexdisk.Operation, error) {
+	if m.MoveAsyncFunc == nil {
+		panic(notSet("MoveAsync"))
+	}
+	return m.MoveAsyncFunc(ctx, fromPath, toPath, overwrite)
+}
+
+func (m *MockDisk) DeleteContext(ctx context.Context, path string, permanently bool) error {
+	if m.DeleteContextFunc == nil {
+		panic(notSet("DeleteContext"))
+	}
+	return m.DeleteContextFunc(ctx, path, permanently)
+}
+
+func (m *MockDisk) DeleteAsync(ctx context.Context, path string, permanently bool) (*yandexdisk.Operation, error) {
+	if m.DeleteAsyncFunc == nil {
+		panic(notSet("DeleteAsync"))
+	}
+	return m.DeleteAsyncFunc(ctx, path, permanently)
+}
+
+func (m *MockDisk) GetOperationStatusContext(ctx context.Context, operationID string) (*yandexdisk.Operation, error) {
+	if m.GetOperationStatusContextFunc == nil {
+		panic(notSet("GetOperationStatusContext"))
+	}
+	return m.GetOperationStatusContextFunc(ctx, operationID)
+}
+
+func (m *MockDisk) WaitOperation(ctx context.Context, op *yandexdisk.Operation, opts *yandexdisk.WaitOptions) (*yandexdisk.Operation, error) {
+	if m.WaitOperationFunc == nil {
+		panic(notSet("WaitOperation"))
+	}
+	return m.WaitOperationFunc(ctx, op, opts)
+}
+
+func (m *MockDisk) Upload(ctx context.Context, r io.Reader, remotePath string, opts *yandexdisk.UploadOptions) (*yandexdisk.UploadResult, error) {
+	if m.UploadFunc == nil {
+		panic(notSet("Upload"))
+	}
+	return m.UploadFunc(ctx, r, remotePath, opts)
+}
+
+func (m *MockDisk) UploadFileContext(ctx context.Context, localFilePath, remotePath string, overwrite bool) (*yandexdisk.UploadResult, error) {
+	if m.UploadFileContextFunc == nil {
+		panic(notSet("UploadFileContext"))
+	}
+	return m.UploadFileContextFunc(ctx, localFilePath, remotePath, overwrite)
+}
+
+func (m *MockDisk) UploadFromURLContext(ctx context.Context, fileURL, remotePath string, disableRedirects bool) (*yandexdisk.Operation, error) {
+	if m.UploadFromURLContextFunc == nil {
+		panic(notSet("UploadFromURLContext"))
+	}
+	return m.UploadFromURLContextFunc(ctx, fileURL, remotePath, disableRedirects)
+}
+
+func (m *MockDisk) Download(ctx context.Context, remotePath string) (io.ReadCloser, *yandexdisk.Resource, error) {
+	if m.DownloadFunc == nil {
+		panic(notSet("Download"))
+	}
+	return m.DownloadFunc(ctx, remotePath)
+}
+
+func (m *MockDisk) DownloadRange(ctx context.Context, remotePath string, offset, length int64) (io.ReadCloser, error) {
+	if m.DownloadRangeFunc == nil {
+		panic(notSet("DownloadRange"))
+	}
+	return m.DownloadRangeFunc(ctx, remotePath, offset, length)
+}
+
+func (m *MockDisk) DownloadTo(ctx context.Context, remotePath string, w io.Writer) (int64, error) {
+	if m.DownloadToFunc == nil {
+		panic(notSet("DownloadTo"))
+	}
+	return m.DownloadToFunc(ctx, remotePath, w)
+}
+
+func (m *MockDisk) DownloadFileContext(ctx context.Context, remotePath, localPath string) error {
+	if m.DownloadFileContextFunc == nil {
+		panic(notSet("DownloadFileContext"))
+	}
+	return m.DownloadFileContextFunc(ctx, remotePath, localPath)
+}
+
+func (m *MockDisk) DownloadFileParallel(ctx context.Context, remotePath, localPath string, opts *yandexdisk.ParallelOptions) error {
+	if m.DownloadFileParallelFunc == nil {
+		panic(notSet("DownloadFileParallel"))
+	}
+	return m.DownloadFileParallelFunc(ctx, remotePath, localPath, opts)
+}
+
+func (m *MockDisk) GetTrashContext(ctx context.Context, path string, limit, offset int) (*yandexdisk.Resource, error) {
+	if m.GetTrashContextFunc == nil {
+		panic(notSet("GetTrashContext"))
+	}
+	return m.GetTrashContextFunc(ctx, path, limit, offset)
+}
+
+func (m *MockDisk) RestoreFromTrashContext(ctx context.Context, path string, name *string, overwrite bool) (*yandexdisk.Resource, error) {
+	if m.RestoreFromTrashContextFunc == nil {
+		panic(notSet("RestoreFromTrashContext"))
+	}
+	return m.RestoreFromTrashContextFunc(ctx, path, name, overwrite)
+}
+
+func (m *MockDisk) ClearTrashContext(ctx context.Context, path *string) error {
+	if m.ClearTrashContextFunc == nil {
+		panic(notSet("ClearTrashContext"))
+	}
+	return m.ClearTrashContextFunc(ctx, path)
+}
+
+func (m *MockDisk) ClearTrashAsync(ctx context.Context, path *string) (*yandexdisk.Operation, error) {
+	if m.ClearTrashAsyncFunc == nil {
+		panic(notSet("ClearTrashAsync"))
+	}
+	return m.ClearTrashAsyncFunc(ctx, path)
+}
+
+func (m *MockDisk) PublishContext(ctx context.Context, path string) (*yandexdisk.Resource, error) {
+	if m.PublishContextFunc == nil {
+		panic(notSet("PublishContext"))
+	}
+	return m.PublishContextFunc(ctx, path)
+}
+
+func (m *MockDisk) UnpublishContext(ctx context.Context, path string) (*yandexdisk.Resource, error) {
+	if m.UnpublishContextFunc == nil {
+		panic(notSet("UnpublishContext"))
+	}
+	return m.UnpublishContextFunc(ctx, path)
+}
+
+func (m *MockDisk) GetPublicResourceMetaContext(ctx context.Context, publicKey string, params map[string]string) (*yandexdisk.Resource, error) {
+	if m.GetPublicResourceMetaContextFunc == nil {
+		panic(notSet("GetPublicResourceMetaContext"))
+	}
+	return m.GetPublicResourceMetaContextFunc(ctx, publicKey, params)
+}
+
+func (m *MockDisk) DownloadPublic(ctx context.Context, publicKey string, path *string) (io.ReadCloser, error) {
+	if m.DownloadPublicFunc == nil {
+		panic(notSet("DownloadPublic"))
+	}
+	return m.DownloadPublicFunc(ctx, publicKey, path)
+}
+
+func (m *MockDisk) DownloadPublicTo(ctx context.Context, publicKey string, path *string, w io.Writer) (int64, error) {
+	if m.DownloadPublicToFunc == nil {
+		panic(notSet("DownloadPublicTo"))
+	}
+	return m.DownloadPublicToFunc(ctx, publicKey, path, w)
+}
+
+func (m *MockDisk) DownloadPublicResourceContext(ctx context.Context, publicKey, localPath string, path *string) error {
+	if m.DownloadPublicResourceContextFunc == nil {
+		panic(notSet("DownloadPublicResourceContext"))
+	}
+	return m.DownloadPublicResourceContextFunc(ctx, publicKey, localPath, path)
+}
+
+func (m *MockDisk) SavePublicResourceContext(ctx context.Context, publicKey string, name, path *string) (*yandexdisk.Resource, error) {
+	if m.SavePublicResourceContextFunc == nil {
+		panic(notSet("SavePublicResourceContext"))
+	}
+	return m.SavePublicResourceContextFunc(ctx, publicKey, name, path)
+}
+
+func (m *MockDisk) GetAvailablePublicSettingsContext(ctx context.Context) (*yandexdisk.AvailablePublicSettings, error) {
+	if m.GetAvailablePublicSettingsContextFunc == nil {
+		panic(notSet("GetAvailablePublicSettingsContext"))
+	}
+	return m.GetAvailablePublicSettingsContextFunc(ctx)
+}
+
+func (m *MockDisk) GetPublicSettingsContext(ctx context.Context, path string, allowAddressAccess bool) (*yandexdisk.PublicSettings, error) {
+	if m.GetPublicSettingsContextFunc == nil {
+		panic(notSet("GetPublicSettingsContext"))
+	}
+	return m.GetPublicSettingsContextFunc(ctx, path, allowAddressAccess)
+}
+
+func (m *MockDisk) ChangePublicSettingsContext(ctx context.Context, path string, settings *yandexdisk.PublicSettings) (*yandexdisk.PublicSettings, error) {
+	if m.ChangePublicSettingsContextFunc == nil {
+		panic(notSet("ChangePublicSettingsContext"))
+	}
+	return m.ChangePublicSettingsContextFunc(ctx, path, settings)
+}
+
+func (m *MockDisk) GetPublicResourcesOwnedByUserContext(ctx context.Context, userID, orgID string, limit, offset int) (*yandexdisk.FilesList, error) {
+	if m.GetPublicResourcesOwnedByUserContextFunc == nil {
+		panic(notSet("GetPublicResourcesOwnedByUserContext"))
+	}
+	return m.GetPublicResourcesOwnedByUserContextFunc(ctx, userID, orgID, limit, offset)
+}
+
+func (m *MockDisk) GetPublicResourcesAccessedByUserContext(ctx context.Context, userID, orgID string, includeGroupAccess bool, limit int, iterationKey *string) (*yandexdisk.FilesList, error) {
+	if m.GetPublicResourcesAccessedByUserContextFunc == nil {
+		panic(notSet("GetPublicResourcesAccessedByUserContext"))
+	}
+	return m.GetPublicResourcesAccessedByUserContextFunc(ctx, userID, orgID, includeGroupAccess, limit, iterationKey)
+}
+
+func (m *MockDisk) UnpublishUserResourceContext(ctx context.Context, publicKey, userID, orgID string) error {
+	if m.UnpublishUserResourceContextFunc == nil {
+		panic(notSet("UnpublishUserResourceContext"))
+	}
+	return m.UnpublishUserResourceContextFunc(ctx, publicKey, userID, orgID)
+}
@@ -0,0 +1,132 @@
+package yandexdisktest
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	yandexdisk "github.com/tigusigalpa/yandex-disk-go"
+)
+
+// ensureFolder is the kind of helper consumer code writes against the
+// interfaces instead of *yandexdisk.Client.
+func ensureFolder(ctx context.Context, d yandexdisk.MetaAPI, path string) error {
+	_, err := d.GetMetaContext(ctx, path, nil)
+	if err == nil {
+		return nil
+	}
+	_, err = d.CreateFolderContext(ctx, path)
+	return err
+}
+
+func TestMockDisk(t *testing.T) {
+	var created []string
+	mock := &MockDisk{
+		GetMetaContextFunc: func(ctx context.Context, path string, params map[string]string) (*yandexdisk.Resource, error) {
+			return nil, &yandexdisk.APIError{StatusCode: 404}
+		},
+		CreateFolderContextFunc: func(ctx context.Context, path string) (*yandexdisk.Resource, error) {
+			created = append(created, path)
+			return &yandexdisk.Resource{Path: path, Type: "dir"}, nil
+		},
+	}
+
+	assert.NoError(t, ensureFolder(context.Background(), mock, "/backups"))
+	assert.Equal(t, []string{"/backups"}, created)
+
+	assert.PanicsWithValue(t, "yandexdisktest: MockDisk.DeleteContextFunc is not set", func() {
+		mock.DeleteContext(context.Background(), "/backups", false)
+	})
+}
+
+func TestClientAndMockShareInterface(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	for _, d := range []yandexdisk.Disk{srv.NewClient(), &MockDisk{
+		GetMetaContextFunc: func(ctx context.Context, path string, params map[string]string) (*yandexdisk.Resource, error) {
+			return &yandexdisk.Resource{Path: "disk:" + path, Type: "dir"}, nil
+		},
+	}} {
+		assert.NoError(t, ensureFolder(context.Background(), d, "/shared"))
+	}
+	assert.Equal(t, 1, srv.RequestCount("PUT", "/resources"))
+}
+
+// TestMockDiskCoversDisk keeps MockDisk in sync with the yandexdisk.Disk
+// interface: every method needs a field of the same signature and every
+// field a method.
+func TestMockDiskCoversDisk(t *testing.T) {
+	disk := reflect.TypeOf((*yandexdisk.Disk)(nil)).Elem()
+	mock := reflect.TypeOf(MockDisk{})
+
+	for i := 0; i < disk.NumMethod(); i++ {
+		method := disk.Method(i)
+		field, ok := mock.FieldByName(method.Name + "Func")
+		if assert.True(t, ok, "MockDisk has no field %sFunc", method.Name) {
+			assert.Equal(t, method.Type, field.Type, "MockDisk.%sFunc", method.Name)
+		}
+	}
+	for i := 0; i < mock.NumField(); i++ {
+		name := strings.TrimSuffix(mock.Field(i).Name, "Func")
+		_, ok := disk.MethodByName(name)
+		assert.True(t, ok, "MockDisk.%s has no Disk method", mock.Field(i).Name)
+	}
+}
+
+func TestHelpersAcceptMock(t *testing.T) {
+	files := map[string]string{"/site/index.html": "<h1>Hello</h1>"}
+	var ranges [][2]int64
+	mock := &MockDisk{
+		GetMetaContextFunc: func(ctx context.Context, path string, params map[string]string) (*yandexdisk.Resource, error) {
+			if content, ok := files[path]; ok {
+				return &yandexdisk.Resource{Name: "index.html", Path: path, Type: yandexdisk.ResourceTypeFile, Size: int64(len(content))}, nil
+			}
+			if path != "/site" {
+				return nil, &yandexdisk.APIError{StatusCode: 404}
+			}
+			dir := &yandexdisk.Resource{Name: "site", Path: path, Type: yandexdisk.ResourceTypeDir, Embedded: &yandexdisk.Embedded{Total: 1}}
+			if params["offset"] == "0" {
+				dir.Embedded.Items = []yandexdisk.Resource{{Name: "index.html", Path: "/site/index.html", Type: yandexdisk.ResourceTypeFile}}
+			}
+			return dir, nil
+		},
+		DownloadRangeFunc: func(ctx context.Context, remotePath string, offset, length int64) (io.ReadCloser, error) {
+			ranges = append(ranges, [2]int64{offset, length})
+			content := files[remotePath][offset:]
+			if length >= 0 {
+				content = content[:length]
+			}
+			return io.NopCloser(strings.NewReader(content)), nil
+		},
+	}
+	ctx := context.Background()
+
+	var walked []string
+	err := yandexdisk.Walk(ctx, mock, "/site", func(path string, resource *yandexdisk.Resource, err error) error {
+		walked = append(walked, path)
+		return err
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/site", "/site/index.html"}, walked)
+
+	f, err := yandexdisk.NewFS(ctx, mock, "/site").Open("index.html")
+	assert.NoError(t, err)
+	f.(io.Seeker).Seek(4, io.SeekStart)
+	data, err := io.ReadAll(f)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello</h1>", string(data))
+
+	remote, err := yandexdisk.OpenRemoteFile(ctx, mock, "/site/index.html")
+	assert.NoError(t, err)
+	part := make([]byte, 5)
+	_, err = remote.ReadAt(part, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(part))
+
+	// Only the ranges read are requested, not the whole file.
+	assert.Equal(t, [][2]int64{{4, -1}, {0, 14}}, ranges)
+}
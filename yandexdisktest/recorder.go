@@ -0,0 +1,337 @@
+package yandexdisktest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode selects whether a Recorder talks to the network.
+type Mode int
+
+const (
+	// ModeReplay answers requests from the cassette and fails requests
+	// that were not recorded.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the network and records them,
+	// replacing the cassette on Stop.
+	ModeRecord
+	// ModeRecordOnce replays the cassette if it exists and records it
+	// otherwise.
+	ModeRecordOnce
+)
+
+// scrubbedHost is the host of the placeholders that replace signed hrefs in
+// a cassette.
+const scrubbedHost = "scrubbed.invalid"
+
+// Recorder is an http.RoundTripper that records interactions with the API to
+// a cassette file and replays them, for use with yandexdisk.WithTransport.
+//
+// Cassettes are safe to commit: the Authorization header is not recorded,
+// and signed upload and download hrefs and redirect locations are replaced
+// by placeholders in both the responses that return them and the requests
+// made to them. Recorded
+// requests are matched by method, path and query parameters, each
+// interaction being replayed once in the order it was recorded.
+type Recorder struct {
+	mode      Mode
+	path      string
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette cassette
+	used     []bool
+	hrefs    map[string]string
+}
+
+type cassette struct {
+	Interactions []interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	body
+}
+
+type recordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	body
+}
+
+// body holds text as is and binary data base64-encoded.
+type body struct {
+	Body       string `json:"body,omitempty"`
+	BodyBase64 string `json:"body_base64,omitempty"`
+}
+
+func newBody(data []byte) body {
+	if utf8.Valid(data) {
+		return body{Body: string(data)}
+	}
+	return body{BodyBase64: base64.StdEncoding.EncodeToString(data)}
+}
+
+func (b body) bytes() ([]byte, error) {
+	if b.BodyBase64 != "" {
+		return base64.StdEncoding.DecodeString(b.BodyBase64)
+	}
+	return []byte(b.Body), nil
+}
+
+// NewRecorder returns a Recorder for the cassette at path. In record mode
+// requests are sent with transport, or http.DefaultTransport if it is nil.
+// Call Stop to save a recording.
+func NewRecorder(path string, mode Mode, transport http.RoundTripper) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	r := &Recorder{mode: mode, path: path, transport: transport, hrefs: map[string]string{}}
+
+	if mode == ModeRecordOnce {
+		r.mode = ModeReplay
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			r.mode = ModeRecord
+		}
+	}
+	if r.mode != ModeReplay {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cassette: %w", err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// Recording reports whether r sends requests to the network.
+func (r *Recorder) Recording() bool {
+	return r.mode == ModeRecord
+}
+
+// Stop saves the cassette if r is recording.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = data
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req)
+	}
+	return r.record(req, reqBody)
+}
+
+func (r *Recorder) record(req *http.Request, reqBody []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(reqBody))
+	if reqBody == nil {
+		out.Body = nil
+	}
+	resp, err := r.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	header := req.Header.Clone()
+	header.Del("Authorization")
+	// After a redirect the Referer is the previous, possibly signed, URL.
+	header.Del("Referer")
+	respHeader := resp.Header.Clone()
+	respHeader.Del("Set-Cookie")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	respData := r.scrub(req, data)
+	if location := respHeader.Get("Location"); location != "" {
+		// A redirect from a signed href usually leads to another signed
+		// URL, which the client requests next. The body of a redirect
+		// repeats the location and is dropped.
+		if u, err := req.URL.Parse(location); err == nil {
+			respHeader.Set("Location", r.placeholder(u.String()))
+		}
+		respHeader.Del("Content-Length")
+		respData = nil
+	}
+
+	reqURL := req.URL.String()
+	if placeholder, ok := r.hrefs[reqURL]; ok {
+		reqURL = placeholder
+	}
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction{
+		Request: recordedRequest{
+			Method: req.Method,
+			URL:    reqURL,
+			Header: header,
+			body:   newBody(reqBody),
+		},
+		Response: recordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     respHeader,
+			body:       newBody(respData),
+		},
+	})
+	return resp, nil
+}
+
+// scrub replaces signed hrefs in a JSON response body with placeholders:
+// the href of upload and download links, and the "file" and "preview" links
+// of resources. The client still receives the original body.
+func (r *Recorder) scrub(req *http.Request, data []byte) []byte {
+	var v interface{}
+	if json.Unmarshal(data, &v) != nil {
+		return data
+	}
+
+	// Other responses with an href, such as the operation returned by
+	// POST /resources/upload, link to the API itself.
+	signedHref := req.Method == http.MethodGet &&
+		(strings.HasSuffix(req.URL.Path, "/resources/upload") || strings.HasSuffix(req.URL.Path, "/resources/download"))
+	if obj, ok := v.(map[string]interface{}); ok && signedHref {
+		r.replaceLink(obj, "href")
+	}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			r.replaceLink(v, "file")
+			r.replaceLink(v, "preview")
+			for _, child := range v {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(v)
+
+	scrubbed, err := json.Marshal(v)
+	if err != nil {
+		return data
+	}
+	return scrubbed
+}
+
+// replaceLink replaces the URL obj[key] with a placeholder and remembers the
+// mapping, so that requests to the URL are recorded under the placeholder.
+func (r *Recorder) replaceLink(obj map[string]interface{}, key string) {
+	href, ok := obj[key].(string)
+	if !ok || href == "" {
+		return
+	}
+	obj[key] = r.placeholder(href)
+}
+
+// placeholder returns the placeholder that stands for the URL href.
+func (r *Recorder) placeholder(href string) string {
+	placeholder, ok := r.hrefs[href]
+	if !ok {
+		placeholder = "https://" + scrubbedHost + "/" + strconv.Itoa(len(r.hrefs)+1)
+		r.hrefs[href] = placeholder
+	}
+	return placeholder
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || !matches(in.Request, req) {
+			continue
+		}
+		r.used[i] = true
+
+		data, err := in.Response.bytes()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode recorded body: %w", err)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(data)),
+			ContentLength: int64(len(data)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("yandexdisktest: no recorded interaction for %s %s", req.Method, req.URL)
+}
+
+// matches compares the method, path and query parameters of a request.
+func matches(recorded recordedRequest, req *http.Request) bool {
+	if recorded.Method != req.Method {
+		return false
+	}
+	u, err := url.Parse(recorded.URL)
+	if err != nil || u.Path != req.URL.Path {
+		return false
+	}
+	query := req.URL.Query()
+	if len(u.Query()) == 0 && len(query) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(u.Query(), query)
+}
@@ -0,0 +1,151 @@
+package yandexdisktest
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	yandexdisk "github.com/tigusigalpa/yandex-disk-go"
+)
+
+// session uploads, inspects and downloads a file and returns what it read.
+func session(t *testing.T, client *yandexdisk.Client) (*yandexdisk.Resource, string) {
+	ctx := context.Background()
+	_, err := client.Upload(ctx, bytes.NewReader([]byte("recorded content")), "/file.txt", nil)
+	assert.NoError(t, err)
+	resource, err := client.GetMeta("/file.txt", nil)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	_, err = client.DownloadTo(ctx, "/file.txt", &buf)
+	assert.NoError(t, err)
+	return resource, buf.String()
+}
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "session.json")
+	srv := NewServer(WithToken("secret-token"))
+
+	recorder, err := NewRecorder(path, ModeRecordOnce, nil)
+	assert.NoError(t, err)
+	assert.True(t, recorder.Recording())
+	recorded, content := session(t, srv.NewClient(yandexdisk.WithTransport(recorder)))
+	assert.Equal(t, "recorded content", content)
+	assert.NoError(t, recorder.Stop())
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-token")
+	assert.NotContains(t, string(data), "/download/")
+	assert.NotContains(t, string(data), "/upload/")
+	assert.Contains(t, string(data), scrubbedHost)
+
+	// The server is gone; everything is answered from the cassette.
+	recorder, err = NewRecorder(path, ModeRecordOnce, nil)
+	assert.NoError(t, err)
+	assert.False(t, recorder.Recording())
+	client := yandexdisk.NewClient("", yandexdisk.WithBaseURL(srv.URL), yandexdisk.WithTransport(recorder),
+		yandexdisk.WithRetryPolicy(yandexdisk.RetryPolicy{}))
+	replayed, content := session(t, client)
+	assert.Equal(t, "recorded content", content)
+	assert.Equal(t, recorded, replayed)
+
+	_, err = client.GetMeta("/other.txt", nil)
+	assert.ErrorContains(t, err, "no recorded interaction for GET")
+}
+
+func TestRecorderMatchesQuery(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddFile("/a.txt", []byte("a"))
+	srv.AddFile("/b.txt", []byte("b"))
+
+	path := filepath.Join(t.TempDir(), "query.json")
+	recorder, _ := NewRecorder(path, ModeRecord, nil)
+	client := srv.NewClient(yandexdisk.WithTransport(recorder))
+	client.GetMeta("/a.txt", nil)
+	client.GetMeta("/b.txt", nil)
+	assert.NoError(t, recorder.Stop())
+
+	recorder, err := NewRecorder(path, ModeReplay, nil)
+	assert.NoError(t, err)
+	client = srv.NewClient(yandexdisk.WithTransport(recorder))
+	b, err := client.GetMeta("/b.txt", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "b.txt", b.Name)
+	a, err := client.GetMeta("/a.txt", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "a.txt", a.Name)
+}
+
+func TestRecorderReplaysUploadFromURL(t *testing.T) {
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("remote content"))
+	}))
+	defer source.Close()
+	srv := NewServer()
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "upload-url.json")
+	wait := &yandexdisk.WaitOptions{Interval: time.Millisecond}
+	run := func(client *yandexdisk.Client) *yandexdisk.Operation {
+		op, err := client.UploadFromURL(source.URL, "/remote.txt", false)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, op.ID())
+		op, err = client.WaitOperation(context.Background(), op, wait)
+		assert.NoError(t, err)
+		return op
+	}
+
+	recorder, _ := NewRecorder(path, ModeRecord, nil)
+	recorded := run(srv.NewClient(yandexdisk.WithTransport(recorder)))
+	assert.NoError(t, recorder.Stop())
+
+	recorder, err := NewRecorder(path, ModeReplay, nil)
+	assert.NoError(t, err)
+	replayed := run(srv.NewClient(yandexdisk.WithTransport(recorder)))
+	assert.Equal(t, recorded, replayed)
+	assert.True(t, replayed.IsSuccess())
+}
+
+func TestRecorderScrubsRedirects(t *testing.T) {
+	var api *httptest.Server
+	api = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/resources/download":
+			w.Write([]byte(`{"href": "` + api.URL + `/get?sig=first", "method": "GET"}`))
+		case "/get":
+			http.Redirect(w, r, "/storage?sig=second", http.StatusFound)
+		case "/storage":
+			w.Write([]byte("redirected content"))
+		}
+	}))
+	defer api.Close()
+
+	path := filepath.Join(t.TempDir(), "redirect.json")
+	download := func(client *yandexdisk.Client) string {
+		var buf bytes.Buffer
+		_, err := client.DownloadTo(context.Background(), "/file.txt", &buf)
+		assert.NoError(t, err)
+		return buf.String()
+	}
+
+	recorder, _ := NewRecorder(path, ModeRecord, nil)
+	assert.Equal(t, "redirected content", download(yandexdisk.NewClient("token", yandexdisk.WithBaseURL(api.URL), yandexdisk.WithTransport(recorder))))
+	assert.NoError(t, recorder.Stop())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "sig=")
+
+	recorder, err = NewRecorder(path, ModeReplay, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "redirected content", download(yandexdisk.NewClient("token", yandexdisk.WithBaseURL(api.URL), yandexdisk.WithTransport(recorder))))
+}
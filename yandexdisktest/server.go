@@ -0,0 +1,387 @@
+// Package yandexdisktest provides an in-memory fake of the Yandex Disk REST
+// API for testing code built on yandexdisk.Client without network access.
+//
+// The fake keeps a file tree, a Trash and published resources in memory and
+// serves the resource, upload, download, trash, publishing and operation
+// endpoints the client uses. Faults such as rate limiting, server errors and
+// latency can be injected per endpoint.
+//
+// Recorder complements the fake: it records interactions with the real API
+// once and replays them in later runs. MockDisk stubs individual calls of
+// the yandexdisk.Disk interface; it is hand-written rather than generated,
+// so using it needs no code generator.
+package yandexdisktest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	yandexdisk "github.com/tigusigalpa/yandex-disk-go"
+)
+
+// DefaultTotalSpace is the disk size reported by a Server created without
+// WithTotalSpace.
+const DefaultTotalSpace = 10 << 30
+
+// DefaultToken is the token used by Server.NewClient when the server was
+// created without WithToken.
+const DefaultToken = "test-token"
+
+// Server is a fake Yandex Disk API served by an httptest.Server. Use URL as
+// the base URL of a client, or create one with NewClient.
+type Server struct {
+	*httptest.Server
+
+	token      string
+	totalSpace int64
+	latency    time.Duration
+	asyncPolls int
+	async      bool
+
+	mu         sync.Mutex
+	disk       tree
+	trash      tree
+	uploads    map[string]upload
+	downloads  map[string]download
+	operations map[string]*operation
+	faults     []*Fault
+	requests   []request
+	revision   int64
+	requestSeq int
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithToken makes the server reject API requests that are not authorized
+// with token. By default any token is accepted.
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithTotalSpace sets the disk size. Uploads that do not fit are rejected
+// with 507 Insufficient Storage.
+func WithTotalSpace(size int64) Option {
+	return func(s *Server) {
+		s.totalSpace = size
+	}
+}
+
+// WithLatency delays every response by d.
+func WithLatency(d time.Duration) Option {
+	return func(s *Server) {
+		s.latency = d
+	}
+}
+
+// WithAsyncOperations makes copy, move, delete, restore and Trash clearing
+// answer 202 Accepted with an operation, as the API does for large folders.
+// Each operation reports "in-progress" for polls status requests, then
+// performs the work and reports "success" or "failed".
+func WithAsyncOperations(polls int) Option {
+	return func(s *Server) {
+		s.async = true
+		s.asyncPolls = polls
+	}
+}
+
+// NewServer starts a fake API with an empty disk. Close it when done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		totalSpace: DefaultTotalSpace,
+		disk:       newTree(),
+		trash:      newTree(),
+		uploads:    map[string]upload{},
+		downloads:  map[string]download{},
+		operations: map[string]*operation{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewClient returns a client for the server. opts are applied after the
+// base URL, so they can override it.
+func (s *Server) NewClient(opts ...yandexdisk.Option) *yandexdisk.Client {
+	token := s.token
+	if token == "" {
+		token = DefaultToken
+	}
+	opts = append([]yandexdisk.Option{yandexdisk.WithBaseURL(s.URL)}, opts...)
+	return yandexdisk.NewClient(token, opts...)
+}
+
+// AddFolder creates the folder at path together with any missing parents.
+// Paths may be given as "disk:/a/b" or "/a/b".
+func (s *Server) AddFolder(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mkdirAll(diskPath(path))
+}
+
+// AddFile stores data at path, creating missing parent folders and
+// replacing an existing file.
+func (s *Server) AddFile(path string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := diskPath(path)
+	s.mkdirAll(parentPath(p))
+	s.writeFile(p, data)
+}
+
+// File returns a copy of the content of the file at path.
+func (s *Server) File(path string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, ok := s.disk[diskPath(path)]
+	if !ok || n.dir {
+		return nil, false
+	}
+	return bytes.Clone(n.data), true
+}
+
+// Fault makes matching requests fail or respond slowly.
+type Fault struct {
+	// Method matches any method if empty.
+	Method string
+	// Path matches the request path exactly, or as a prefix if it ends with
+	// a slash. Upload and download hrefs are served under "/upload/" and
+	// "/download/". An empty Path matches every request.
+	Path string
+	// Status is the response status, for example 429 or 503. If zero the
+	// request is only delayed.
+	Status int
+	// RetryAfter is sent in the Retry-After header, rounded up to seconds.
+	RetryAfter time.Duration
+	// Delay is waited before responding.
+	Delay time.Duration
+	// Times is the number of requests the fault applies to. Zero means
+	// every matching request until ClearFaults is called.
+	Times int
+}
+
+func (f *Fault) matches(r *http.Request) bool {
+	return matchRequest(f.Method, f.Path, r.Method, r.URL.Path)
+}
+
+// AddFault injects f into the requests that follow. Faults are checked in
+// the order they were added and the first matching one applies.
+func (s *Server) AddFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// takeFault returns the first fault matching r and uses it up.
+func (s *Server) takeFault(r *http.Request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, f := range s.faults {
+		if !f.matches(r) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		fault := *f
+		return &fault
+	}
+	return nil
+}
+
+type request struct {
+	method string
+	path   string
+}
+
+// RequestCount returns the number of requests received, including failed
+// ones, that match method and path as a Fault would.
+func (s *Server) RequestCount(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, req := range s.requests {
+		if matchRequest(method, path, req.method, req.path) {
+			count++
+		}
+	}
+	return count
+}
+
+// ExpireLinks invalidates all upload and download hrefs handed out so far.
+// Later requests to them fail with 410 Gone, like expired links of the real
+// API.
+func (s *Server) ExpireLinks() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, u := range s.uploads {
+		u.expired = true
+		s.uploads[id] = u
+	}
+	for id, d := range s.downloads {
+		d.expired = true
+		s.downloads[id] = d
+	}
+}
+
+func matchRequest(method, pattern, reqMethod, reqPath string) bool {
+	if method != "" && method != reqMethod {
+		return false
+	}
+	if strings.HasSuffix(pattern, "/") && pattern != "/" {
+		return strings.HasPrefix(reqPath, pattern)
+	}
+	return pattern == "" || pattern == reqPath
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, request{method: r.Method, path: r.URL.Path})
+	s.requestSeq++
+	w.Header().Set("Yandex-Cloud-Request-ID", "fake-"+strconv.Itoa(s.requestSeq))
+	s.mu.Unlock()
+
+	delay := s.latency
+	fault := s.takeFault(r)
+	if fault != nil {
+		delay += fault.Delay
+	}
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if fault != nil && fault.Status != 0 {
+		if fault.RetryAfter > 0 {
+			seconds := (fault.RetryAfter + time.Second - 1) / time.Second
+			w.Header().Set("Retry-After", strconv.Itoa(int(seconds)))
+		}
+		code := "InternalServerError"
+		if fault.Status == http.StatusTooManyRequests {
+			code = "TooManyRequestsError"
+		}
+		writeError(w, &apiError{status: fault.Status, code: code, message: http.StatusText(fault.Status)})
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/upload/"):
+		s.handleUpload(w, r)
+		return
+	case strings.HasPrefix(r.URL.Path, "/download/"):
+		s.handleDownload(w, r)
+		return
+	}
+
+	if s.token != "" && r.Header.Get("Authorization") != "OAuth "+s.token {
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	// Uploads from a URL fetch the file without holding the lock, so the
+	// URL may point back at this server.
+	if r.Method == "POST" && r.URL.Path == "/resources/upload" {
+		s.handleUploadFromURL(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	handler := s.route(r)
+	if handler == nil {
+		writeError(w, &apiError{
+			status:  http.StatusNotImplemented,
+			code:    "NotImplementedError",
+			message: fmt.Sprintf("%s %s is not supported by the fake server.", r.Method, r.URL.Path),
+		})
+		return
+	}
+	if err := handler(w, r); err != nil {
+		writeError(w, err)
+	}
+}
+
+// route returns the handler for an API request. Handlers run with s.mu held.
+func (s *Server) route(r *http.Request) func(http.ResponseWriter, *http.Request) *apiError {
+	if r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/operations/") {
+		return s.handleOperation
+	}
+
+	switch r.Method + " " + r.URL.Path {
+	case "GET /":
+		return s.handleDiskInfo
+	case "GET /resources":
+		return s.handleGetResource
+	case "PUT /resources":
+		return s.handleCreateFolder
+	case "PATCH /resources":
+		return s.handlePatchResource
+	case "DELETE /resources":
+		return s.handleDelete
+	case "GET /resources/files":
+		return s.handleFiles
+	case "GET /resources/last-uploaded":
+		return s.handleLastUploaded
+	case "GET /resources/public":
+		return s.handlePublished
+	case "POST /resources/copy":
+		return s.handleCopy
+	case "POST /resources/move":
+		return s.handleMove
+	case "PUT /resources/publish":
+		return s.handlePublish
+	case "PUT /resources/unpublish":
+		return s.handleUnpublish
+	case "GET /resources/upload":
+		return s.handleUploadLink
+	case "GET /resources/download":
+		return s.handleDownloadLink
+	case "GET /public/resources":
+		return s.handlePublicResource
+	case "GET /public/resources/download":
+		return s.handlePublicDownloadLink
+	case "POST /public/resources/save":
+		return s.handleSavePublic
+	case "GET /trash/resources":
+		return s.handleGetTrash
+	case "DELETE /trash/resources":
+		return s.handleClearTrash
+	case "PUT /trash/resources/restore":
+		return s.handleRestore
+	}
+	return nil
+}
+
+// newID returns a random hex string for hrefs, keys and resource IDs.
+func newID() string {
+	data := make([]byte, 12)
+	if _, err := rand.Read(data); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(data)
+}
@@ -0,0 +1,321 @@
+package yandexdisktest
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	yandexdisk "github.com/tigusigalpa/yandex-disk-go"
+)
+
+var fastRetries = yandexdisk.WithRetryPolicy(yandexdisk.RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     time.Millisecond,
+	Multiplier:     1,
+})
+
+func TestUploadDownload(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.NewClient(yandexdisk.WithChecksumVerification())
+	ctx := context.Background()
+
+	_, err := client.CreateFolder("/docs")
+	assert.NoError(t, err)
+	_, err = client.CreateFolder("/docs")
+	assert.ErrorIs(t, err, yandexdisk.ErrAlreadyExists)
+
+	result, err := client.Upload(ctx, bytes.NewReader([]byte("hello")), "/docs/a.txt", nil)
+	assert.NoError(t, err)
+	assert.True(t, result.Success)
+	_, err = client.Upload(ctx, bytes.NewReader([]byte("again")), "/docs/a.txt", nil)
+	assert.ErrorIs(t, err, yandexdisk.ErrAlreadyExists)
+
+	resource, err := client.GetMeta("disk:/docs/a.txt", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "a.txt", resource.Name)
+	assert.Equal(t, "disk:/docs/a.txt", resource.Path)
+	assert.Equal(t, int64(5), resource.Size)
+	assert.Equal(t, "text/plain", resource.MimeType)
+
+	var buf bytes.Buffer
+	_, err = client.DownloadTo(ctx, "/docs/a.txt", &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", buf.String())
+
+	file, err := client.Open(ctx, "/docs/a.txt")
+	assert.NoError(t, err)
+	part := make([]byte, 3)
+	_, err = file.ReadAt(part, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "llo", string(part))
+	file.Close()
+
+	_, err = client.GetMeta("/docs/missing.txt", nil)
+	assert.ErrorIs(t, err, yandexdisk.ErrNotFound)
+	_, err = client.Upload(ctx, bytes.NewReader(nil), "/missing/a.txt", nil)
+	assert.ErrorIs(t, err, yandexdisk.ErrNotFound)
+}
+
+func TestListing(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.NewClient()
+
+	srv.AddFile("/photos/b.jpg", []byte("bb"))
+	srv.AddFile("/photos/a.jpg", []byte("a"))
+	srv.AddFile("/photos/c.jpg", []byte("ccc"))
+	srv.AddFolder("/photos/albums")
+
+	dir, err := client.GetMeta("/photos", map[string]string{"limit": "2", "offset": "1", "sort": "-size"})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, dir.GetTotalItems())
+	var names []string
+	for _, item := range dir.GetItems() {
+		names = append(names, item.Name)
+	}
+	assert.Equal(t, []string{"b.jpg", "a.jpg"}, names)
+
+	files, err := client.GetAllFiles(10, 0)
+	assert.NoError(t, err)
+	assert.Len(t, files.Items, 3)
+	assert.Equal(t, yandexdisk.MediaTypeImage, files.Items[0].MediaType)
+
+	fsys := client.FS(context.Background(), "/photos")
+	assert.NoError(t, fstest.TestFS(fsys, "a.jpg", "b.jpg", "c.jpg", "albums"))
+}
+
+func TestCopyMoveTrash(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.NewClient()
+
+	srv.AddFile("/src/file.txt", []byte("data"))
+	srv.AddFolder("/dst")
+
+	copied, err := client.Copy("/src", "/dst/copy", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "disk:/dst/copy", copied.Path)
+	data, ok := srv.File("/dst/copy/file.txt")
+	assert.True(t, ok)
+	assert.Equal(t, "data", string(data))
+
+	_, err = client.Move("/src/file.txt", "/dst/copy/file.txt", false)
+	assert.ErrorIs(t, err, yandexdisk.ErrAlreadyExists)
+	_, err = client.Move("/src/file.txt", "/dst/copy/file.txt", true)
+	assert.NoError(t, err)
+	_, ok = srv.File("/src/file.txt")
+	assert.False(t, ok)
+
+	assert.NoError(t, client.Delete("/dst/copy", false))
+	_, err = client.GetMeta("/dst/copy", nil)
+	assert.ErrorIs(t, err, yandexdisk.ErrNotFound)
+
+	trash, err := client.GetTrash("trash:/", 20, 0)
+	assert.NoError(t, err)
+	assert.Len(t, trash.GetItems(), 1)
+	assert.Equal(t, "trash:/copy", trash.GetItems()[0].Path)
+
+	_, err = client.RestoreFromTrash("trash:/copy", nil, false)
+	assert.NoError(t, err)
+	_, ok = srv.File("/dst/copy/file.txt")
+	assert.True(t, ok)
+
+	assert.NoError(t, client.Delete("/dst", false))
+	assert.NoError(t, client.ClearTrash(nil))
+	trash, err = client.GetTrash("trash:/", 20, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, trash.GetItems())
+}
+
+func TestPublish(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.NewClient()
+	ctx := context.Background()
+
+	srv.AddFile("/shared/report.pdf", []byte("report"))
+	_, err := client.Publish("/shared")
+	assert.NoError(t, err)
+
+	shared, err := client.GetMeta("/shared", nil)
+	assert.NoError(t, err)
+	assert.True(t, shared.IsPublished())
+
+	sub := "/report.pdf"
+	meta, err := client.GetPublicResourceMeta(shared.PublicURL, map[string]string{"path": sub})
+	assert.NoError(t, err)
+	assert.Equal(t, "/report.pdf", meta.Path)
+
+	var buf bytes.Buffer
+	_, err = client.DownloadPublicTo(ctx, shared.PublicKey, &sub, &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "report", buf.String())
+
+	_, err = client.SavePublicResource(shared.PublicKey, nil, nil)
+	assert.NoError(t, err)
+	_, ok := srv.File("/Downloads/shared/report.pdf")
+	assert.True(t, ok)
+
+	_, err = client.Unpublish("/shared")
+	assert.NoError(t, err)
+	_, err = client.GetPublicResourceMeta(shared.PublicKey, nil)
+	assert.ErrorIs(t, err, yandexdisk.ErrNotFound)
+}
+
+func TestCustomProperties(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.NewClient()
+
+	srv.AddFile("/file.txt", []byte("x"))
+	_, err := client.AddMeta("/file.txt", map[string]interface{}{"project": "alpha", "stage": "draft"})
+	assert.NoError(t, err)
+	resource, err := client.AddMeta("/file.txt", map[string]interface{}{"stage": nil})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"project": "alpha"}, resource.CustomProperties)
+}
+
+func TestFaults(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.NewClient(fastRetries)
+	srv.AddFile("/file.txt", []byte("x"))
+
+	srv.AddFault(Fault{Method: "GET", Path: "/resources", Status: http.StatusTooManyRequests, Times: 2})
+	_, err := client.GetMeta("/file.txt", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, srv.RequestCount("GET", "/resources"))
+
+	srv.AddFault(Fault{Path: "/download/", Status: http.StatusServiceUnavailable})
+	_, err = client.DownloadTo(context.Background(), "/file.txt", io.Discard)
+	assert.Error(t, err)
+	assert.True(t, yandexdisk.IsRetryable(err))
+	assert.Equal(t, 3, srv.RequestCount("GET", "/download/"))
+
+	srv.ClearFaults()
+	srv.AddFault(Fault{Delay: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.GetMetaContext(ctx, "/file.txt", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAsyncOperations(t *testing.T) {
+	srv := NewServer(WithAsyncOperations(2))
+	defer srv.Close()
+	client := srv.NewClient(yandexdisk.WithWaitOptions(yandexdisk.WaitOptions{Interval: time.Millisecond}))
+	ctx := context.Background()
+	srv.AddFile("/a/file.txt", []byte("x"))
+
+	op, err := client.MoveAsync(ctx, "/a", "/b", false)
+	assert.NoError(t, err)
+	assert.NotNil(t, op)
+	_, ok := srv.File("/b/file.txt")
+	assert.False(t, ok)
+
+	op, err = client.WaitOperation(ctx, op, nil)
+	assert.NoError(t, err)
+	assert.True(t, op.IsSuccess())
+	_, ok = srv.File("/b/file.txt")
+	assert.True(t, ok)
+	assert.Equal(t, 3, srv.RequestCount("GET", "/operations/"))
+
+	assert.NoError(t, client.Delete("/b", true))
+	_, err = client.GetMeta("/b", nil)
+	assert.ErrorIs(t, err, yandexdisk.ErrNotFound)
+}
+
+func TestUploadFromURL(t *testing.T) {
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("remote content"))
+	}))
+	defer source.Close()
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.NewClient()
+
+	op, err := client.UploadFromURL(source.URL, "/remote.txt", false)
+	assert.NoError(t, err)
+	_, err = client.WaitOperation(context.Background(), op, &yandexdisk.WaitOptions{Interval: time.Millisecond})
+	assert.NoError(t, err)
+	data, _ := srv.File("/remote.txt")
+	assert.Equal(t, "remote content", string(data))
+}
+
+func TestTokenAndQuota(t *testing.T) {
+	srv := NewServer(WithToken("secret"), WithTotalSpace(4))
+	defer srv.Close()
+
+	_, err := yandexdisk.NewClient("wrong", yandexdisk.WithBaseURL(srv.URL)).GetCapacity()
+	assert.ErrorIs(t, err, yandexdisk.ErrUnauthorized)
+
+	client := srv.NewClient()
+	_, err = client.Upload(context.Background(), bytes.NewReader([]byte("too large")), "/big.bin", nil)
+	assert.ErrorIs(t, err, yandexdisk.ErrInsufficientStorage)
+	var transferErr *yandexdisk.TransferError
+	assert.True(t, errors.As(err, &transferErr))
+	assert.Equal(t, http.StatusInsufficientStorage, transferErr.StatusCode)
+	assert.NotEmpty(t, transferErr.RequestID)
+
+	srv.AddFile("/small.bin", []byte("abc"))
+	info, err := client.GetCapacity()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), info.TotalSpace)
+	assert.Equal(t, int64(3), info.UsedSpace)
+}
+
+func TestExpireLinks(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.NewClient()
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	srv.AddFile("/file.bin", content)
+
+	file, err := client.Open(context.Background(), "/file.bin")
+	assert.NoError(t, err)
+	defer file.Close()
+	buf := make([]byte, 10)
+	_, err = file.ReadAt(buf, 0)
+	assert.NoError(t, err)
+	srv.ExpireLinks()
+
+	// The expired href is replaced by a fresh one.
+	_, err = file.ReadAt(buf, 90000)
+	assert.NoError(t, err)
+	assert.Equal(t, content[90000:90010], buf)
+	assert.Equal(t, 2, srv.RequestCount("GET", "/resources/download"))
+	assert.Equal(t, 3, srv.RequestCount("GET", "/download/"))
+}
+
+func TestDownloadFileDiscardsStalePartialFile(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddFile("/file.txt", []byte("NEWCONTENT-NEWCONTENT"))
+	client := srv.NewClient()
+
+	localPath := filepath.Join(t.TempDir(), "file.txt")
+	old := md5.Sum([]byte("OLDCONTENT-OLDCONTENT"))
+	for _, validator := range []string{"", fmt.Sprintf(`"%x"`, old)} {
+		assert.NoError(t, os.WriteFile(localPath+".part", []byte("OLDCONT"), 0644))
+		if validator != "" {
+			assert.NoError(t, os.WriteFile(localPath+".part.validator", []byte(validator), 0644))
+		}
+
+		assert.NoError(t, client.DownloadFile("/file.txt", localPath))
+		data, _ := os.ReadFile(localPath)
+		assert.Equal(t, "NEWCONTENT-NEWCONTENT", string(data))
+	}
+}
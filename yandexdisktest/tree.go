@@ -0,0 +1,293 @@
+package yandexdisktest
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// timeFormat is the timestamp format of the API.
+const timeFormat = "2006-01-02T15:04:05-07:00"
+
+type node struct {
+	dir        bool
+	data       []byte
+	md5        string
+	sha256     string
+	created    time.Time
+	modified   time.Time
+	resourceID string
+	revision   int64
+	props      map[string]interface{}
+	publicKey  string
+
+	// Set on the top node of a Trash entry.
+	originPath string
+	deleted    time.Time
+}
+
+// tree maps clean absolute paths to nodes. The root "/" is always a folder.
+type tree map[string]*node
+
+func newTree() tree {
+	now := time.Now()
+	return tree{"/": {dir: true, created: now, modified: now, resourceID: newID()}}
+}
+
+// children returns the paths of the direct children of p sorted by name.
+func (t tree) children(p string) []string {
+	var paths []string
+	for k := range t {
+		if k != "/" && parentPath(k) == p {
+			paths = append(paths, k)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// files returns the paths of all files sorted by path.
+func (t tree) files() []string {
+	var paths []string
+	for k, n := range t {
+		if !n.dir {
+			paths = append(paths, k)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (t tree) size() int64 {
+	var size int64
+	for _, n := range t {
+		size += int64(len(n.data))
+	}
+	return size
+}
+
+// detach removes p and everything below it and returns them as a tree whose
+// root is p.
+func (t tree) detach(p string) tree {
+	sub := tree{}
+	for k, n := range t {
+		if k == p || strings.HasPrefix(k, p+"/") {
+			sub["/"+strings.TrimPrefix(k[len(p):], "/")] = n
+			delete(t, k)
+		}
+	}
+	return sub
+}
+
+// attach inserts sub with its root at p.
+func (t tree) attach(p string, sub tree) {
+	for k, n := range sub {
+		if k == "/" {
+			t[p] = n
+		} else {
+			t[p+k] = n
+		}
+	}
+}
+
+// clone returns a deep copy of the subtree at p with new resource IDs.
+func (t tree) clone(p string) tree {
+	sub := tree{}
+	now := time.Now()
+	for k, n := range t {
+		if k == p || strings.HasPrefix(k, p+"/") {
+			c := *n
+			c.resourceID = newID()
+			c.created, c.modified = now, now
+			c.publicKey = ""
+			c.props = cloneProps(n.props)
+			sub["/"+strings.TrimPrefix(k[len(p):], "/")] = &c
+		}
+	}
+	return sub
+}
+
+func cloneProps(props map[string]interface{}) map[string]interface{} {
+	if props == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(props))
+	for k, v := range props {
+		c[k] = v
+	}
+	return c
+}
+
+// diskPath cleans a path given as "disk:/a", "/a" or "a".
+func diskPath(p string) string {
+	return path.Clean("/" + strings.TrimPrefix(p, "disk:"))
+}
+
+// trashPath cleans a path given as "trash:/a", "/a" or "a".
+func trashPath(p string) string {
+	return path.Clean("/" + strings.TrimPrefix(p, "trash:"))
+}
+
+func parentPath(p string) string {
+	return path.Dir(p)
+}
+
+func (s *Server) touch(n *node) {
+	s.revision++
+	n.revision = s.revision
+	n.modified = time.Now()
+}
+
+// mkdirAll creates the folder p and its missing parents.
+func (s *Server) mkdirAll(p string) {
+	if n, ok := s.disk[p]; ok && n.dir {
+		return
+	}
+	s.mkdirAll(parentPath(p))
+	s.mkdir(p)
+}
+
+func (s *Server) mkdir(p string) {
+	n := &node{dir: true, created: time.Now(), resourceID: newID()}
+	s.touch(n)
+	s.disk[p] = n
+}
+
+// writeFile stores data at p, replacing an existing file.
+func (s *Server) writeFile(p string, data []byte) {
+	n, ok := s.disk[p]
+	if !ok || n.dir {
+		n = &node{created: time.Now(), resourceID: newID()}
+		s.disk[p] = n
+	}
+	md5sum := md5.Sum(data)
+	sha256sum := sha256.Sum256(data)
+	n.data = data
+	n.md5 = hex.EncodeToString(md5sum[:])
+	n.sha256 = hex.EncodeToString(sha256sum[:])
+	s.touch(n)
+}
+
+// resource is the JSON representation of a resource in the API.
+type resource struct {
+	Name             string                 `json:"name"`
+	Path             string                 `json:"path"`
+	Type             string                 `json:"type"`
+	Size             int64                  `json:"size,omitempty"`
+	Created          string                 `json:"created"`
+	Modified         string                 `json:"modified"`
+	MimeType         string                 `json:"mime_type,omitempty"`
+	MediaType        string                 `json:"media_type,omitempty"`
+	MD5              string                 `json:"md5,omitempty"`
+	SHA256           string                 `json:"sha256,omitempty"`
+	PublicKey        string                 `json:"public_key,omitempty"`
+	PublicURL        string                 `json:"public_url,omitempty"`
+	ResourceID       string                 `json:"resource_id"`
+	Revision         int64                  `json:"revision"`
+	CustomProperties map[string]interface{} `json:"custom_properties,omitempty"`
+	OriginPath       string                 `json:"origin_path,omitempty"`
+	Deleted          string                 `json:"deleted,omitempty"`
+	Embedded         *embedded              `json:"_embedded,omitempty"`
+}
+
+type embedded struct {
+	Sort   string     `json:"sort"`
+	Path   string     `json:"path"`
+	Items  []resource `json:"items"`
+	Limit  int        `json:"limit"`
+	Offset int        `json:"offset"`
+	Total  int        `json:"total"`
+}
+
+// newResource describes n, which is shown as name at the API path apiPath.
+func newResource(n *node, name, apiPath string) resource {
+	res := resource{
+		Name:             name,
+		Path:             apiPath,
+		Type:             "file",
+		Created:          n.created.Format(timeFormat),
+		Modified:         n.modified.Format(timeFormat),
+		ResourceID:       n.resourceID,
+		Revision:         n.revision,
+		CustomProperties: n.props,
+	}
+	if n.dir {
+		res.Type = "dir"
+	} else {
+		res.Size = int64(len(n.data))
+		res.MimeType = mimeType(res.Name)
+		res.MediaType = mediaType(res.MimeType)
+		res.MD5 = n.md5
+		res.SHA256 = n.sha256
+	}
+	if n.publicKey != "" {
+		res.PublicKey = n.publicKey
+		res.PublicURL = publicURL(n.publicKey)
+	}
+	if n.originPath != "" {
+		res.OriginPath = "disk:" + n.originPath
+		res.Deleted = n.deleted.Format(timeFormat)
+	}
+	return res
+}
+
+func publicURL(key string) string {
+	return "https://yadi.sk/d/" + key
+}
+
+func mimeType(name string) string {
+	if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+		return strings.SplitN(t, ";", 2)[0]
+	}
+	return "application/octet-stream"
+}
+
+func mediaType(mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return "image"
+	case strings.HasPrefix(mimeType, "video/"):
+		return "video"
+	case strings.HasPrefix(mimeType, "audio/"):
+		return "audio"
+	case strings.HasPrefix(mimeType, "text/"):
+		return "text"
+	case mimeType == "application/pdf":
+		return "document"
+	case mimeType == "application/zip":
+		return "compressed"
+	}
+	return "unknown"
+}
+
+// sortPaths orders paths of t by the "sort" parameter of the API: name,
+// path, created, modified or size, reversed with a leading minus.
+func (t tree) sortPaths(paths []string, by string) {
+	desc := strings.HasPrefix(by, "-")
+	by = strings.TrimPrefix(by, "-")
+	less := func(a, b string) bool {
+		na, nb := t[a], t[b]
+		switch by {
+		case "path":
+			return a < b
+		case "created":
+			return na.created.Before(nb.created)
+		case "modified":
+			return na.modified.Before(nb.modified)
+		case "size":
+			return len(na.data) < len(nb.data)
+		}
+		return path.Base(a) < path.Base(b)
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		if desc {
+			return less(paths[j], paths[i])
+		}
+		return less(paths[i], paths[j])
+	})
+}